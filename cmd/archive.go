@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal"
+	"github.com/wakatara/harsh/internal/storage"
+	"github.com/wakatara/harsh/internal/ui"
+)
+
+var (
+	archiveBefore string
+	archiveGzip   bool
+	archiveDryRun bool
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive --before <date>",
+	Short: "Move old log entries into yearly archives",
+	Long: "Moves log entries dated before the given date into yearly archives next to the log (log.2023, or\n" +
+		"log.2023.gz with --gzip), keeping the log short and quick to load. Archives are read back automatically\n" +
+		"by commands that need older entries, such as log stats and log --json.",
+	Example: `  harsh archive --before 2024-01-01
+  harsh archive --before 2024-01-01 --gzip --dry-run`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if archiveBefore == "" {
+			return fmt.Errorf("--before is required, e.g. --before %d-01-01", civil.DateOf(time.Now()).Year)
+		}
+		before, err := parseDateArg(archiveBefore)
+		if err != nil {
+			return err
+		}
+
+		h := getHarsh()
+		result, err := storage.ArchiveLog(h.GetRepository().GetLogDir(), before, archiveGzip, archiveDryRun)
+		if err != nil {
+			return err
+		}
+
+		display := ui.NewDisplay(!color.Enable)
+		display.ShowArchiveResult(result, before, archiveDryRun)
+		return nil
+	},
+}
+
+func init() {
+	archiveCmd.Flags().StringVarP(&archiveBefore, "before", "b", "", "archive entries dated before this date, YYYY-MM-DD")
+	archiveCmd.Flags().BoolVarP(&archiveGzip, "gzip", "z", false, "compress new archives with gzip")
+	archiveCmd.Flags().BoolVarP(&archiveDryRun, "dry-run", "n", false, "preview the archive without writing it")
+}
+
+// loadHistory adds archived entries from the given date onwards to h, for
+// commands that look further back than the log itself. A zero date loads
+// the whole history.
+func loadHistory(h *internal.Harsh, from civil.Date) error {
+	warnings, err := h.LoadHistory(from)
+	ui.NewDisplay(!color.Enable).ShowWarnings(warnings)
+	return err
+}
@@ -24,7 +24,7 @@ var askCmd = &cobra.Command{
 		h := getHarsh()
 		input := ui.NewInput(!color.Enable)
 		input.AskHabits(
-			h.GetHabits(),
+			h.GetTaggedHabits(tags),
 			h.GetEntries(),
 			h.GetRepository(),
 			h.GetMaxHabitNameLength(),
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal/storage"
+	"github.com/wakatara/harsh/internal/ui"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change harsh settings",
+	Long: "Shows and changes the settings in the config file next to your habits and log. Settings are the\n" +
+		"defaults harsh runs with: HARSH_ environment variables override them, and command line flags override both.",
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List settings, their values and where they come from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		display := ui.NewDisplay(!color.Enable)
+		display.ShowSettings(settings, paths)
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get <key>",
+	Short:             "Show the value of a setting",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: configKeyValidArgs,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := settings.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the config file",
+	Example: `  harsh config set color never
+  harsh config set graph-days 60
+  harsh config set symbols ascii`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: configKeyValidArgs,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := storage.SetSetting(paths.ConfigDir, args[0], args[1]); err != nil {
+			return err
+		}
+		s, _, err := storage.LoadSettings(paths.ConfigDir)
+		if err != nil {
+			return err
+		}
+		value, _ := s.Get(args[0])
+		fmt.Printf("Set %s to %s.\n", args[0], value)
+		if source := s.Source(args[0]); source != "config" {
+			fmt.Printf("Note: %s is overridden by %s.\n", args[0], source)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+}
+
+func configKeyValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return storage.SettingKeys(), cobra.ShellCompDirectiveNoFileComp
+}
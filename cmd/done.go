@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal/storage"
+)
+
+var (
+	doneDate    string
+	doneAmount  string
+	doneComment string
+	doneTime    string
+)
+
+var doneCmd = &cobra.Command{
+	Use:   "done <habit> [y|n|s]",
+	Short: "Record a habit without prompting",
+	Long: "Records a single habit result without an interactive prompt, for use from scripts, cron jobs and hooks.\n" +
+		"The habit may be its full name or a fragment matching exactly one habit. The result defaults to y.",
+	Example: `  harsh done gym
+  harsh done "Called Mom" n --date yday
+  harsh done pullups y --amount 25 --comment "New personal record"
+  harsh done meds --time 07:10`,
+	Aliases:           []string{"d"},
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: doneCmdValidArgs,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result := "y"
+		if len(args) > 1 {
+			result = args[1]
+		}
+		if result != "y" && result != "n" && result != "s" {
+			return fmt.Errorf("invalid result %q (expected y, n or s)", result)
+		}
+
+		d, err := parseDateArg(doneDate)
+		if err != nil {
+			return err
+		}
+
+		if doneAmount != "" {
+			if _, err := strconv.ParseFloat(doneAmount, 64); err != nil {
+				return fmt.Errorf("invalid amount %q (expected a number)", doneAmount)
+			}
+		}
+		comment := strings.TrimSpace(doneComment)
+
+		// Entries for today are stamped with the current time unless one is given
+		timeOfDay := ""
+		if doneTime != "" {
+			if timeOfDay, err = storage.ParseTimeOfDay(doneTime); err != nil {
+				return err
+			}
+		} else if d == civil.DateOf(time.Now()) {
+			timeOfDay = time.Now().Format(storage.TimeLayout)
+		}
+
+		h := getHarsh()
+		habit, err := storage.FindHabit(h.GetHabits(), args[0])
+		if err != nil {
+			return err
+		}
+		if habit.HasEnded(d) {
+			return fmt.Errorf("habit %q ended on %s", habit.Name, habit.EndRecord)
+		}
+		if !habit.HasStarted(d) {
+			return fmt.Errorf("habit %q starts on %s", habit.Name, habit.StartRecord)
+		}
+
+		if err := h.GetRepository().WriteEntry(d, habit.Name, result, comment, doneAmount, timeOfDay); err != nil {
+			return err
+		}
+		fmt.Printf("%s : %s : %s\n", d, habit.Name, result)
+		return nil
+	},
+}
+
+func init() {
+	doneCmd.Flags().StringVarP(&doneDate, "date", "d", "", `date to record, YYYY-MM-DD or "yday" (defaults to today)`)
+	doneCmd.Flags().StringVarP(&doneAmount, "amount", "a", "", "optional amount to record")
+	doneCmd.Flags().StringVarP(&doneComment, "comment", "m", "", "optional comment to record")
+	doneCmd.Flags().StringVarP(&doneTime, "time", "t", "", "time of day the habit was done, HH:MM (defaults to now for today)")
+}
+
+// parseDateArg parses a date argument as YYYY-MM-DD, "today" or "yday".
+// An empty argument means today. Future dates are rejected.
+func parseDateArg(arg string) (civil.Date, error) {
+	today := civil.DateOf(time.Now())
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "", "today":
+		return today, nil
+	case "yday", "yd", "yesterday":
+		return today.AddDays(-1), nil
+	}
+
+	d, err := civil.ParseDate(arg)
+	if err != nil {
+		return civil.Date{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or yday)", arg)
+	}
+	if d.After(today) {
+		return civil.Date{}, fmt.Errorf("date %s is in the future", d)
+	}
+	return d, nil
+}
+
+func doneCmdValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) == 1 {
+		return []cobra.Completion{"y", "n", "s"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	out := []cobra.Completion{}
+	h := getHarsh()
+	for _, habit := range h.GetHabits() {
+		if strings.Contains(habit.Name, toComplete) {
+			out = append(out, habit.Name)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
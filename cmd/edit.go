@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/civil"
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal"
+	"github.com/wakatara/harsh/internal/storage"
+	"github.com/wakatara/harsh/internal/ui"
+)
+
+var (
+	editAmount  string
+	editComment string
+	editTime    string
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <habit> <date> [y|n|s]",
+	Short: "Change a recorded habit entry",
+	Long: "Changes the recorded result, amount or comment of an existing log entry in place.\n" +
+		"Without a result or flags, prompts for the new value. Unspecified fields, including the time, keep their current values.",
+	Example: `  harsh edit gym 2025-03-02
+  harsh edit gym yday n --comment "knee hurt"`,
+	Aliases:           []string{"e"},
+	Args:              cobra.RangeArgs(2, 3),
+	ValidArgsFunction: editCmdValidArgs,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := parseDateArg(args[1])
+		if err != nil {
+			return err
+		}
+
+		h := getHarsh()
+		habitName, outcome, err := resolveLoggedHabit(h, args[0], d)
+		if err != nil {
+			return err
+		}
+
+		result := outcome.Result
+		amount := outcome.AmountString()
+		comment := outcome.Comment
+		timeOfDay := outcome.Time
+
+		interactive := len(args) < 3 && !cmd.Flags().Changed("amount") && !cmd.Flags().Changed("comment") && !cmd.Flags().Changed("time")
+		if interactive {
+			input := ui.NewInput(!color.Enable)
+			newResult, newAmount, newComment, ok := input.AskEntry(d, habitName, outcome, h.GetMaxHabitNameLength())
+			if !ok {
+				return nil
+			}
+			// An amount or comment left out of the answer keeps the current one
+			result = newResult
+			if newAmount != "" {
+				amount = newAmount
+			}
+			if newComment != "" {
+				comment = newComment
+			}
+		} else {
+			if len(args) > 2 {
+				result = args[2]
+			}
+			if cmd.Flags().Changed("amount") {
+				amount = editAmount
+			}
+			if cmd.Flags().Changed("comment") {
+				comment = strings.TrimSpace(editComment)
+			}
+			if cmd.Flags().Changed("time") {
+				timeOfDay = ""
+				// An empty --time clears the recorded time
+				if editTime != "" {
+					if timeOfDay, err = storage.ParseTimeOfDay(editTime); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if result != "y" && result != "n" && result != "s" {
+			return fmt.Errorf("invalid result %q (expected y, n or s)", result)
+		}
+		if amount != "" {
+			if _, err := strconv.ParseFloat(amount, 64); err != nil {
+				return fmt.Errorf("invalid amount %q (expected a number)", amount)
+			}
+		}
+
+		if err := h.GetRepository().UpdateEntry(d, habitName, result, comment, amount, timeOfDay); err != nil {
+			return err
+		}
+		fmt.Printf("%s : %s : %s\n", d, habitName, result)
+		return nil
+	},
+}
+
+func init() {
+	editCmd.Flags().StringVarP(&editAmount, "amount", "a", "", "new amount to record")
+	editCmd.Flags().StringVarP(&editComment, "comment", "m", "", "new comment to record")
+	editCmd.Flags().StringVarP(&editTime, "time", "t", "", `new time of day, HH:MM ("" to clear)`)
+}
+
+func editCmdValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return doneCmdValidArgs(cmd, args, toComplete)
+	case 1:
+		return []cobra.Completion{"today", "yday"}, cobra.ShellCompDirectiveNoFileComp
+	case 2:
+		return []cobra.Completion{"y", "n", "s"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// resolveLoggedHabit finds the habit and outcome logged on a day, in the log
+// or the archives. The habit is resolved against the habits file first,
+// falling back to an exact name in the log so entries for habits since
+// removed from the habits file can still be changed.
+func resolveLoggedHabit(h *internal.Harsh, name string, d civil.Date) (string, storage.Outcome, error) {
+	if err := loadHistory(h, d); err != nil {
+		return "", storage.Outcome{}, err
+	}
+	entries := *h.GetEntries()
+	habit, err := storage.FindHabit(h.GetHabits(), name)
+	if err != nil {
+		if outcome, ok := entries[storage.DailyHabit{Day: d, Habit: name}]; ok {
+			return name, outcome, nil
+		}
+		return "", storage.Outcome{}, err
+	}
+
+	outcome, ok := entries[storage.DailyHabit{Day: d, Habit: habit.Name}]
+	if !ok {
+		return "", storage.Outcome{}, fmt.Errorf("%w for %s on %s", storage.ErrEntryNotFound, habit.Name, d)
+	}
+	return habit.Name, outcome, nil
+}
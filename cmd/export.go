@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal"
+	"github.com/wakatara/harsh/internal/storage"
+	"github.com/wakatara/harsh/internal/ui"
+)
+
+var (
+	exportFrom   string
+	exportTo     string
+	exportOutput string
+	exportTodo   bool
+	exportAlarm  string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export your habit history for other tools",
+	Long: "Exports your habits and their history, including yearly archives, for use in other tools. Output\n" +
+		"goes to standard output, or with --output to a file that is replaced in one go, so apps reading it\n" +
+		"never see it half written.",
+}
+
+var exportCSVCmd = &cobra.Command{
+	Use:   "csv [habit-fragment]",
+	Short: "Export the log as CSV with each day's status",
+	Long: "Exports one row per habit and day with the columns date, habit, heading, result, status, amount,\n" +
+		"comment and time. Status is what the graph shows for the day: done, skip, satisfied, skipified,\n" +
+		"break, warning or unrecorded.",
+	Example: `  harsh export csv > harsh.csv
+  harsh export csv gym --from 2024-01-01 --to 2024-12-31`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExportTable(',', args)
+	},
+}
+
+var exportTSVCmd = &cobra.Command{
+	Use:   "tsv [habit-fragment]",
+	Short: "Export the log as TSV with each day's status",
+	Long:  "Exports the same table as export csv, separated by tabs.",
+	Example: `  harsh export tsv > harsh.tsv
+  harsh export tsv --from 2025-01-01`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExportTable('\t', args)
+	},
+}
+
+var exportICSCmd = &cobra.Command{
+	Use:   "ics [habit-fragment]",
+	Short: "Export an iCalendar feed of upcoming habit deadlines",
+	Long: "Exports a calendar with one all-day event per habit on the date it must next be done to keep its\n" +
+		"streak, with a reminder at --alarm on the day. Broken streaks are due today. Regenerate the file\n" +
+		"regularly, e.g. from cron, and subscribe to it in your calendar app; each habit keeps the same\n" +
+		"event, which moves as you log. Use --todo for to-dos instead of events.",
+	Example: `  harsh export ics --output ~/Calendars/harsh.ics
+  harsh export ics --todo --alarm 07:30 > harsh.ics`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var habitFragment string
+		if len(args) > 0 {
+			habitFragment = args[0]
+		}
+		if exportAlarm != "" {
+			alarm, err := storage.ParseTimeOfDay(exportAlarm)
+			if err != nil {
+				return err
+			}
+			exportAlarm = alarm
+		}
+
+		h := getHarsh()
+		return writeExport(func(w io.Writer) error {
+			return ui.ExportICS(w, h.GetTaggedHabits(tags), h.GetEntries(), habitFragment, hideEnded, exportTodo, exportAlarm)
+		})
+	},
+}
+
+var exportOrgCmd = &cobra.Command{
+	Use:   "org [habit-fragment]",
+	Short: "Export habits as org-mode habits with their logbooks",
+	Long: "Exports each habit as an org-mode TODO with STYLE habit for org-agenda, scheduled on its next due\n" +
+		"date with a repeater from its frequency (3/7 becomes .+2d/3d), and its entries as LOGBOOK state\n" +
+		"changes. harsh import org reads the file back.",
+	Example:      `  harsh export org --output ~/org/habits.org`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var habitFragment string
+		if len(args) > 0 {
+			habitFragment = args[0]
+		}
+
+		h := getHarsh()
+		if err := loadHistory(h, civil.Date{}); err != nil {
+			return err
+		}
+		return writeExport(func(w io.Writer) error {
+			return ui.ExportOrg(w, h.GetTaggedHabits(tags), h.GetEntries(), habitFragment, hideEnded)
+		})
+	},
+}
+
+var exportJSONCmd = &cobra.Command{
+	Use:   "json [habit-fragment]",
+	Short: "Export habits and their whole history as JSON",
+	Long: "Exports the same document as harsh log --json, with every day since each habit's first record\n" +
+		"rather than the last 100. harsh import json reads it back, making it a portable backup.",
+	Example:      `  harsh export json --output harsh-backup.json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var habitFragment string
+		if len(args) > 0 {
+			habitFragment = args[0]
+		}
+
+		h := getHarsh()
+		if err := loadHistory(h, civil.Date{}); err != nil {
+			return err
+		}
+		return writeExport(func(w io.Writer) error {
+			return ui.WriteHabitLogJSON(w, h.GetTaggedHabits(tags), h.GetEntries(), habitFragment, hideEnded, true)
+		})
+	},
+}
+
+func init() {
+	exportCmd.PersistentFlags().StringVarP(&exportOutput, "output", "o", "", "write to this file instead of standard output")
+	for _, cmd := range []*cobra.Command{exportCSVCmd, exportTSVCmd} {
+		cmd.Flags().StringVar(&exportFrom, "from", "", "export from this date, YYYY-MM-DD (default: first entry)")
+		cmd.Flags().StringVar(&exportTo, "to", "", "export up to this date, YYYY-MM-DD (default: today)")
+	}
+	exportICSCmd.Flags().BoolVar(&exportTodo, "todo", false, "write to-dos (VTODO) instead of events (VEVENT)")
+	exportICSCmd.Flags().StringVar(&exportAlarm, "alarm", "09:00", `time of day to be reminded on the due date, HH:MM, or "" for none`)
+	exportCmd.AddCommand(exportCSVCmd)
+	exportCmd.AddCommand(exportTSVCmd)
+	exportCmd.AddCommand(exportICSCmd)
+	exportCmd.AddCommand(exportOrgCmd)
+	exportCmd.AddCommand(exportJSONCmd)
+}
+
+// writeExport runs render against standard output, or the --output file,
+// which is replaced atomically once the export is complete
+func writeExport(render func(w io.Writer) error) error {
+	if exportOutput == "" {
+		return render(os.Stdout)
+	}
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(exportOutput, buf.Bytes(), 0644)
+}
+
+// exportRange parses the --from and --to flags. A zero from means the
+// whole history.
+func exportRange() (from civil.Date, to civil.Date, err error) {
+	to = civil.DateOf(time.Now())
+	if exportTo != "" {
+		if to, err = parseDateArg(exportTo); err != nil {
+			return from, to, err
+		}
+	}
+	if exportFrom != "" {
+		if from, err = parseDateArg(exportFrom); err != nil {
+			return from, to, err
+		}
+		if from.After(to) {
+			return from, to, fmt.Errorf("--from %s is after --to %s", from, to)
+		}
+	}
+	return from, to, nil
+}
+
+// loadExportHistory loads the archived entries an export from from needs,
+// including the habit intervals before it that statuses depend on
+func loadExportHistory(h *internal.Harsh, from civil.Date) error {
+	if !from.IsZero() {
+		longestInterval := 0
+		for _, habit := range h.GetHabits() {
+			longestInterval = max(longestInterval, habit.Interval)
+		}
+		from = from.AddDays(-longestInterval)
+	}
+	return loadHistory(h, from)
+}
+
+// runExportTable writes the CSV or TSV export to standard output
+func runExportTable(separator rune, args []string) error {
+	var habitFragment string
+	if len(args) > 0 {
+		habitFragment = args[0]
+	}
+	from, to, err := exportRange()
+	if err != nil {
+		return err
+	}
+
+	h := getHarsh()
+	if err := loadExportHistory(h, from); err != nil {
+		return err
+	}
+	return writeExport(func(w io.Writer) error {
+		return ui.ExportTable(w, separator, h.GetTaggedHabits(tags), h.GetEntries(), from, to, habitFragment, hideEnded)
+	})
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal/storage"
+)
+
+var habitRenameAlias bool
+
+var habitCmd = &cobra.Command{
+	Use:   "habit",
+	Short: "Manage habits in the habits file",
+}
+
+var habitRenameCmd = &cobra.Command{
+	Use:   "rename <habit> <new name>",
+	Short: "Rename a habit, keeping its history",
+	Long: "Renames a habit in the habits file and rewrites its log entries to the new name, so its graph, streaks\n" +
+		"and stats carry on. With --alias the log is left untouched and the old name is recorded in the habits\n" +
+		"file as a former name instead, e.g. \"Gym (was: Gymmed): 3/7\".",
+	Example: `  harsh habit rename Gymmed Gym
+  harsh habit rename "Called Mom" "Called Parents" --alias`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return doneCmdValidArgs(cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h := getHarsh()
+		habit, err := storage.FindHabit(h.GetHabits(), args[0])
+		if err != nil {
+			return err
+		}
+
+		changed, err := storage.RenameHabit(h.GetRepository().GetConfigDir(), h.GetRepository().GetLogDir(), habit.Name, args[1], habitRenameAlias)
+		if err != nil {
+			return err
+		}
+		if habitRenameAlias {
+			fmt.Printf("Renamed %s to %s, keeping %s as a former name.\n", habit.Name, args[1], habit.Name)
+		} else {
+			fmt.Printf("Renamed %s to %s, %d log entries updated.\n", habit.Name, args[1], changed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	habitRenameCmd.Flags().BoolVar(&habitRenameAlias, "alias", false, "keep the log as is and record the old name as a former name")
+	habitCmd.AddCommand(habitRenameCmd)
+}
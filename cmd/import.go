@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/civil"
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal/storage"
+	"github.com/wakatara/harsh/internal/ui"
+)
+
+var (
+	importDryRun bool
+	importMap    []string
+	importSkip   []string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import habits and history from other habit trackers",
+}
+
+var importLoopCmd = &cobra.Command{
+	Use:   "loop <export>",
+	Short: "Import habits and checkmarks from Loop Habit Tracker",
+	Long: "Imports a Loop Habit Tracker CSV export (Settings > Export as CSV), given as the zip file or the\n" +
+		"directory it unzips to. Loop habits missing from your habits file are added with their frequency,\n" +
+		"e.g. \"3 times per week\" becomes 3/7, and their checkmarks are logged as y, n or s entries. Days already\n" +
+		"in your log are left as they are. Use --map to log a Loop habit under a different or existing harsh\n" +
+		"habit and --skip to leave one out.",
+	Example: `  harsh import loop "Loop Habits CSV 2025-02-01.zip" --dry-run
+  harsh import loop ./loop-export --map "Exercise=Gym" --skip "Drink water"`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := importOptions(importMap, importSkip)
+		if err != nil {
+			return err
+		}
+		imported, err := storage.ReadLoopExport(args[0])
+		if err != nil {
+			return err
+		}
+		return runImport(imported, opts, "Loop Habit Tracker")
+	},
+}
+
+var importOrgCmd = &cobra.Command{
+	Use:   "org <file>",
+	Short: "Import habits and their logbooks from an org-mode file",
+	Long: "Imports org-habit headlines (STYLE habit, or a repeating SCHEDULED timestamp) from an org-mode file,\n" +
+		"such as one written by harsh export org. Habits missing from your habits file are added, under the\n" +
+		"org headline they sit in, with their frequency from the repeater (.+3d becomes 3). DONE state changes\n" +
+		"in their logbooks are logged as y, SKIP as s and MISSED as n, with notes as comments. Days already in\n" +
+		"your log are left as they are.",
+	Example: `  harsh import org ~/org/habits.org --dry-run
+  harsh import org habits.org --map "Go to the gym=Gym"`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := importOptions(importMap, importSkip)
+		if err != nil {
+			return err
+		}
+		imported, err := storage.ReadOrgFile(args[0])
+		if err != nil {
+			return err
+		}
+		return runImport(imported, opts, "Org")
+	},
+}
+
+var importJSONCmd = &cobra.Command{
+	Use:   "json <file>",
+	Short: "Import habits and entries from harsh JSON",
+	Long: "Imports the JSON document written by harsh export json or harsh log --json, given as a file or -\n" +
+		"for standard input. Habits missing from your habits file are added with their heading, frequency\n" +
+		"and end date, and every entry with a result is logged. Days already in your log are left as they\n" +
+		"are, so importing the same backup twice changes nothing.",
+	Example: `  harsh import json harsh-backup.json --dry-run
+  ssh laptop harsh export json | harsh import json -`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := importOptions(importMap, importSkip)
+		if err != nil {
+			return err
+		}
+
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("cannot open JSON file %s: %w", args[0], err)
+			}
+			defer f.Close()
+			r = f
+		}
+		imported, err := ui.ReadHabitLogJSON(r)
+		if err != nil {
+			return err
+		}
+		return runImport(imported, opts, "")
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{importLoopCmd, importOrgCmd, importJSONCmd} {
+		cmd.Flags().BoolVarP(&importDryRun, "dry-run", "n", false, "preview the import without writing it")
+		cmd.Flags().StringArrayVarP(&importMap, "map", "m", nil, `log an imported habit under another name, "Imported name=harsh name"`)
+		cmd.Flags().StringArrayVar(&importSkip, "skip", nil, "leave an imported habit out of the import")
+	}
+	importCmd.AddCommand(importLoopCmd)
+	importCmd.AddCommand(importOrgCmd)
+	importCmd.AddCommand(importJSONCmd)
+}
+
+// importOptions parses the --map and --skip flags
+func importOptions(mappings []string, skip []string) (storage.ImportOptions, error) {
+	opts := storage.ImportOptions{Names: map[string]string{}, Skip: skip}
+	for _, mapping := range mappings {
+		from, to, ok := strings.Cut(mapping, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return opts, fmt.Errorf("invalid --map %q (expected \"Imported name=harsh name\")", mapping)
+		}
+		opts.Names[from] = to
+	}
+	return opts, nil
+}
+
+// runImport plans the import of habits from another tracker against the
+// whole history, then previews or applies it. New habits are added to the
+// habits file under heading.
+func runImport(imported []*storage.ImportedHabit, opts storage.ImportOptions, heading string) error {
+	h := getHarsh()
+	// Entries already archived are not missing from the log
+	if err := loadHistory(h, civil.Date{}); err != nil {
+		return err
+	}
+
+	plan, err := storage.PlanImport(imported, opts, h.GetHabits(), *h.GetEntries())
+	if err != nil {
+		return err
+	}
+	if importDryRun {
+		ui.NewDisplay(!color.Enable).ShowImportPlan(plan)
+		return nil
+	}
+
+	added, err := plan.Apply(h.GetRepository(), heading)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported: %d habits added, %d entries added", len(plan.NewHabits), added)
+	if plan.Existing > 0 {
+		fmt.Printf(", %d days already logged kept", plan.Existing)
+	}
+	fmt.Println(".")
+	if added > 0 {
+		fmt.Println("Run `harsh log tidy` to sort the imported entries into place.")
+	}
+	return nil
+}
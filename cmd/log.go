@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"time"
+
+	"cloud.google.com/go/civil"
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 	"github.com/wakatara/harsh/internal/ui"
@@ -20,9 +23,23 @@ var logCmd = &cobra.Command{
 
 		h := getHarsh()
 
+		// JSON reports streaks and stats over the whole history, while the
+		// graph only needs its own window and the habit intervals before it
+		from := civil.Date{}
+		if !jsonOutput {
+			longestInterval := 0
+			for _, habit := range h.GetHabits() {
+				longestInterval = max(longestInterval, habit.Interval)
+			}
+			from = civil.DateOf(time.Now()).AddDays(-h.GetCountBack() - longestInterval)
+		}
+		if err := loadHistory(h, from); err != nil {
+			return err
+		}
+
 		if jsonOutput {
 			return ui.ShowHabitLogJSON(
-				h.GetHabits(),
+				h.GetTaggedHabits(tags),
 				h.GetEntries(),
 				habitFragment,
 				hideEnded,
@@ -31,7 +48,7 @@ var logCmd = &cobra.Command{
 
 		display := ui.NewDisplay(!color.Enable)
 		display.ShowHabitLog(
-			h.GetHabits(),
+			h.GetTaggedHabits(tags),
 			h.GetEntries(),
 			h.GetCountBack(),
 			h.GetMaxHabitNameLength(),
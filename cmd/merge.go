@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"cloud.google.com/go/civil"
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal/storage"
+	"github.com/wakatara/harsh/internal/ui"
+)
+
+var (
+	mergePrefer string
+	mergeDryRun bool
+	mergeRemove bool
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [file...]",
+	Short: "Merge sync conflicted copies of the log",
+	Long: "Merges entries from conflicted copies of the log left by sync tools into your log. With no files given,\n" +
+		"copies such as \"log (conflicted copy ...)\" and \"log.sync-conflict-*\" are found in the config directory.\n" +
+		"Entries missing from the log are added. When the same habit and day has different outcomes, --prefer\n" +
+		"decides which one is kept: ask (prompt for each), current (the log), incoming (the copy), or done\n" +
+		"(the best result, y over s over n).",
+	Example: `  harsh merge
+  harsh merge --dry-run
+  harsh merge "log (conflicted copy 2025-02-01)" --prefer done --remove`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		choose, err := mergeChooser(mergePrefer)
+		if err != nil {
+			return err
+		}
+
+		h := getHarsh()
+		// Entries already archived are not missing from the log
+		if err := loadHistory(h, civil.Date{}); err != nil {
+			return err
+		}
+		logDir := h.GetRepository().GetLogDir()
+		paths := args
+		if len(paths) == 0 {
+			paths, err = storage.FindConflictedLogs(logDir)
+			if err != nil {
+				return err
+			}
+			if len(paths) == 0 {
+				fmt.Println("No conflicted copies of the log found in " + logDir)
+				return nil
+			}
+		}
+		for _, path := range paths {
+			fmt.Println("Merging " + path)
+		}
+
+		plan, warnings, err := storage.PlanMerge(*h.GetEntries(), filepath.Join(logDir, "log"), paths, h.GetHabits())
+		display := ui.NewDisplay(!color.Enable)
+		display.ShowWarnings(warnings)
+		if err != nil {
+			return err
+		}
+
+		if mergeDryRun {
+			display.ShowMergePlan(plan)
+			return nil
+		}
+
+		added, updated, err := plan.Apply(h.GetRepository(), choose)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Merged: %d entries added, %d updated.\n", added, updated)
+
+		if mergeRemove {
+			for _, path := range paths {
+				if err := os.Remove(path); err != nil {
+					return fmt.Errorf("merged, but could not remove %s: %w", path, err)
+				}
+				fmt.Println("Removed " + path)
+			}
+		}
+		if added > 0 {
+			fmt.Println("Run `harsh log tidy` to sort the merged entries into place.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	mergeCmd.Flags().StringVarP(&mergePrefer, "prefer", "p", "ask", `outcome to keep on conflicts: "ask", "current", "incoming" or "done"`)
+	mergeCmd.Flags().BoolVarP(&mergeDryRun, "dry-run", "n", false, "preview the merge without writing it")
+	mergeCmd.Flags().BoolVar(&mergeRemove, "remove", false, "delete the conflicted copies once merged")
+	mergeCmd.RegisterFlagCompletionFunc("prefer", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return []cobra.Completion{"ask", "current", "incoming", "done"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// mergeChooser returns the conflict rule named by the --prefer flag
+func mergeChooser(prefer string) (func(storage.MergeConflict) (storage.Outcome, error), error) {
+	switch prefer {
+	case "ask":
+		input := ui.NewInput(!color.Enable)
+		return func(c storage.MergeConflict) (storage.Outcome, error) {
+			return input.ChooseConflict(c), nil
+		}, nil
+	case "current":
+		return func(c storage.MergeConflict) (storage.Outcome, error) {
+			return c.Current, nil
+		}, nil
+	case "incoming":
+		return func(c storage.MergeConflict) (storage.Outcome, error) {
+			return c.Incoming, nil
+		}, nil
+	case "done":
+		rank := map[string]int{"n": 0, "s": 1, "y": 2}
+		return func(c storage.MergeConflict) (storage.Outcome, error) {
+			if rank[c.Incoming.Result] > rank[c.Current.Result] {
+				return c.Incoming, nil
+			}
+			return c.Current, nil
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid --prefer %q (expected ask, current, incoming or done)", prefer)
+}
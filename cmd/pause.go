@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal/storage"
+)
+
+var pauseHabits []string
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause <from> <to>",
+	Short: "Pause habits for a range of days, such as a holiday",
+	Long: "Records a pause in the habits file. Paused days count as skipped without anything being logged: they\n" +
+		"are not asked or listed as todos, warn of nothing and leave streaks as they were. Without --habit every\n" +
+		"habit is paused; with it only the habits named.",
+	Example: `  harsh pause 2025-07-01 2025-07-14
+  harsh pause today 2025-07-14 --habit gym --habit run`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pause, err := storage.ParsePause(pauseDateArg(args[0]) + ".." + pauseDateArg(args[1]))
+		if err != nil {
+			return err
+		}
+
+		h := getHarsh()
+		var names []string
+		for _, fragment := range pauseHabits {
+			habit, err := storage.FindHabit(h.GetHabits(), fragment)
+			if err != nil {
+				return err
+			}
+			names = append(names, habit.Name)
+		}
+
+		if err := storage.AddPause(h.GetRepository().GetConfigDir(), pause, names); err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Printf("Paused all habits from %s to %s.\n", pause.From, pause.To)
+		} else {
+			fmt.Printf("Paused %s from %s to %s.\n", strings.Join(names, ", "), pause.From, pause.To)
+		}
+		return nil
+	},
+}
+
+func init() {
+	pauseCmd.Flags().StringSliceVar(&pauseHabits, "habit", nil, "habit to pause, by name or fragment (repeat for several; defaults to all)")
+	pauseCmd.RegisterFlagCompletionFunc("habit", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return doneCmdValidArgs(cmd, nil, toComplete)
+	})
+}
+
+// pauseDateArg resolves "today" and "yday" in a pause date argument, which
+// unlike other dates may lie in the future
+func pauseDateArg(arg string) string {
+	today := civil.DateOf(time.Now())
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "today":
+		return today.String()
+	case "yday", "yd", "yesterday":
+		return today.AddDays(-1).String()
+	}
+	return arg
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <habit> <date>",
+	Short: "Remove a recorded habit entry",
+	Long:  "Removes the log entry for a habit on a date, leaving all other lines of the log untouched.",
+	Example: `  harsh rm gym 2025-03-02
+  harsh rm "Called Mom" yday`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: rmCmdValidArgs,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := parseDateArg(args[1])
+		if err != nil {
+			return err
+		}
+
+		h := getHarsh()
+		habitName, outcome, err := resolveLoggedHabit(h, args[0], d)
+		if err != nil {
+			return err
+		}
+
+		if err := h.GetRepository().DeleteEntry(d, habitName); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s : %s : %s\n", d, habitName, outcome.Result)
+		return nil
+	},
+}
+
+func rmCmdValidArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return doneCmdValidArgs(cmd, args, toComplete)
+	case 1:
+		return []cobra.Completion{"today", "yday"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
@@ -3,16 +3,22 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 	"github.com/wakatara/harsh/internal"
+	"github.com/wakatara/harsh/internal/graph"
+	"github.com/wakatara/harsh/internal/storage"
+	"github.com/wakatara/harsh/internal/ui"
 )
 
 var (
 	colorOption string
 	hideEnded   bool
 	jsonOutput  bool
+	profile     string
+	tags        []string
 	RootCmd     = &cobra.Command{
 		Use:     "harsh",
 		Short:   "habit tracking for geeks",
@@ -29,30 +35,108 @@ var (
 
 var harsh *internal.Harsh
 
+// paths are the directories of the selected profile, and settings are read
+// from its settings file and the environment before any command runs, with
+// command line flags applied over them
+var (
+	paths    storage.Paths
+	settings *storage.Settings
+)
+
 // getHarsh returns the global harsh instance, initializing it lazily if needed.
 // This allows commands like 'version' to run without triggering onboarding.
+// Load failures are fatal here: the commands cannot do anything useful
+// without habits and a log, so we report the error and exit non-zero.
 func getHarsh() *internal.Harsh {
 	if harsh == nil {
-		harsh = internal.NewHarsh()
+		h, err := internal.NewHarsh(paths, settings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		display := ui.NewDisplay(!color.Enable)
+		if h.FirstRun {
+			display.ShowWelcome(h.GetRepository().GetConfigDir(), h.GetRepository().GetLogDir())
+			os.Exit(0)
+		}
+		display.ShowWarnings(h.GetWarnings())
+		harsh = h
 	}
 	return harsh
 }
 
 func init() {
-	RootCmd.PersistentFlags().StringVarP(&colorOption, "color", "C", "auto", `manage colors in output, "always", "never" or "auto" (defaults to auto)`)
-	RootCmd.PersistentFlags().BoolVarP(&hideEnded, "hide-ended", "H", false, "Hide habits that have an end date")
+	RootCmd.PersistentFlags().StringVarP(&colorOption, "color", "C", "auto", `manage colors in output, "always", "never" or "auto" (defaults to the color setting, or auto)`)
+	RootCmd.PersistentFlags().BoolVarP(&hideEnded, "hide-ended", "H", false, "Hide habits that have an end date (defaults to the hide-ended setting)")
 	RootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format (for programmatic use)")
+	RootCmd.PersistentFlags().StringVarP(&profile, "profile", "P", "", "Use the habits and log of a named profile (defaults to HARSH_PROFILE)")
+	RootCmd.PersistentFlags().StringSliceVar(&tags, "tag", nil, "Only show or ask habits with this tag in ask, todo, log and export (repeat or comma separate for any of several)")
 	RootCmd.RegisterFlagCompletionFunc("color", colorCompletionFunc)
+	RootCmd.RegisterFlagCompletionFunc("tag", tagCompletionFunc)
+	RootCmd.RegisterFlagCompletionFunc("profile", profileCompletionFunc)
 	RootCmd.AddCommand(askCmd)
+	RootCmd.AddCommand(doneCmd)
+	RootCmd.AddCommand(editCmd)
+	RootCmd.AddCommand(rmCmd)
+	RootCmd.AddCommand(mergeCmd)
+	RootCmd.AddCommand(habitCmd)
+	RootCmd.AddCommand(pauseCmd)
+	RootCmd.AddCommand(archiveCmd)
+	RootCmd.AddCommand(importCmd)
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(configCmd)
 	RootCmd.AddCommand(todoCmd)
 	RootCmd.AddCommand(logCmd)
 	RootCmd.AddCommand(versionCmd)
 
-	// Add stats as subcommand of log
+	// Add stats, tidy and times as subcommands of log
 	logCmd.AddCommand(statsCmd)
+	logCmd.AddCommand(tidyCmd)
+	logCmd.AddCommand(timesCmd)
 
-	// Set color disable based on color arg, or bas
+	// Load settings, then set color disable based on color arg, or bas
 	cobra.OnInitialize(func() {
+		flags := RootCmd.PersistentFlags()
+		if !flags.Changed("profile") {
+			profile = os.Getenv("HARSH_PROFILE")
+		}
+		var err error
+		paths, err = storage.FindPaths(profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		s, warnings, err := storage.LoadSettings(paths.ConfigDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		settings = s
+		// Flags given on the command line win over settings
+		if flags.Changed("color") {
+			if err := settings.Override("color", colorOption, "--color"); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --color: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		colorOption = settings.Color
+		if flags.Changed("hide-ended") {
+			if err := settings.Override("hide-ended", fmt.Sprint(hideEnded), "--hide-ended"); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --hide-ended: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		hideEnded = settings.HideEnded
+		if err := graph.UseSymbols(settings.Symbols); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := graph.UseWeekStart(settings.WeekStart); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ui.NewDisplay(!color.Enable).ShowWarnings(warnings)
+
 		switch colorOption {
 		case "never":
 			color.Enable = false
@@ -73,6 +157,22 @@ func init() {
 	// This allows 'harsh version' to work without triggering onboarding
 }
 
+func profileCompletionFunc(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return storage.Profiles(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func tagCompletionFunc(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var out []cobra.Completion
+	for _, habit := range getHarsh().GetHabits() {
+		for _, tag := range habit.Tags {
+			if !slices.Contains(out, tag) {
+				out = append(out, tag)
+			}
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
 func colorCompletionFunc(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
 	return []cobra.Completion{"always", "never", "auto"}, cobra.ShellCompDirectiveNoFileComp
 }
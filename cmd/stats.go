@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"cloud.google.com/go/civil"
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 	"github.com/wakatara/harsh/internal/ui"
@@ -13,9 +14,12 @@ var statsCmd = &cobra.Command{
 	Aliases: []string{"s"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		h := getHarsh()
+		if err := loadHistory(h, civil.Date{}); err != nil {
+			return err
+		}
 		display := ui.NewDisplay(!color.Enable)
 		display.ShowHabitStats(
-			h.GetHabits(),
+			h.GetTaggedHabits(tags),
 			h.GetEntries(),
 			h.GetMaxHabitNameLength(),
 			hideEnded,
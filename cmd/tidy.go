@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal/storage"
+	"github.com/wakatara/harsh/internal/ui"
+)
+
+var (
+	tidyDryRun         bool
+	tidyArchiveOrphans bool
+)
+
+var tidyCmd = &cobra.Command{
+	Use:   "tidy",
+	Short: "Sort and deduplicate the log file",
+	Long: "Rewrites the log sorted by date, then by habit in habits file order, collapsing duplicate entries for\n" +
+		"the same habit and day to the last one recorded. The original log is saved as log.bak.",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h := getHarsh()
+		result, err := storage.TidyLog(
+			h.GetRepository().GetLogDir(),
+			h.GetHabits(),
+			tidyArchiveOrphans,
+			tidyDryRun,
+		)
+		if err != nil {
+			return err
+		}
+
+		display := ui.NewDisplay(!color.Enable)
+		display.ShowTidyResult(result, tidyDryRun)
+		return nil
+	},
+}
+
+func init() {
+	tidyCmd.Flags().BoolVarP(&tidyDryRun, "dry-run", "n", false, "preview the changes without writing them")
+	tidyCmd.Flags().BoolVar(&tidyArchiveOrphans, "archive-orphans", false, "move entries for habits not in the habits file to log.orphaned")
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"cloud.google.com/go/civil"
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/wakatara/harsh/internal/ui"
+)
+
+var timesCmd = &cobra.Command{
+	Use:     "times",
+	Short:   "Show the time of day habits are usually done",
+	Long:    "Shows the average time of day each habit is done, from entries with a recorded time, and whether it has been drifting earlier or later recently.",
+	Aliases: []string{"t"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h := getHarsh()
+		if err := loadHistory(h, civil.Date{}); err != nil {
+			return err
+		}
+		display := ui.NewDisplay(!color.Enable)
+		display.ShowTimeStats(
+			h.GetTaggedHabits(tags),
+			h.GetEntries(),
+			h.GetMaxHabitNameLength(),
+			hideEnded,
+		)
+		return nil
+	},
+}
@@ -15,9 +15,10 @@ var todoCmd = &cobra.Command{
 		h := getHarsh()
 		display := ui.NewDisplay(!color.Enable)
 		display.ShowTodos(
-			h.GetHabits(),
+			h.GetTaggedHabits(tags),
 			h.GetEntries(),
 			h.GetMaxHabitNameLength(),
+			h.GetSettings().TodoDays,
 		)
 		return nil
 	},
@@ -6,6 +6,7 @@ require (
 	cloud.google.com/go v0.123.0
 	github.com/gookit/color v1.6.1
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/sys v0.44.0
 	golang.org/x/term v0.43.0
 )
 
@@ -13,5 +14,4 @@ require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
-	golang.org/x/sys v0.44.0 // indirect
 )
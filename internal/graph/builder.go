@@ -27,33 +27,48 @@ func BuildGraph(habit *storage.Habit, entries *storage.Entries, countBack int, a
 		if habit.HasEnded(d) {
 			// Show muted end marker on the first day after the end date
 			if !habit.EndRecord.IsZero() && d == habit.EndRecord.AddDays(1) {
-				graphDay = color.C256(245).Sprint("▏")
+				graphDay = color.C256(245).Sprint(symbols.End)
 			} else {
 				graphDay = " "
 			}
+		} else if !habit.HasStarted(d) {
+			// Before the start date, show muted as not yet started
+			graphDay = color.C256(245).Sprint(symbols.NotStarted)
+		} else if _, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]; !ok && habit.IsPaused(d) {
+			graphDay = symbols.Paused
+		} else if habit.Avoid {
+			graphDay = avoidGraphDay(d, to, habit, *entries)
 		} else if outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]; ok {
 			switch {
+			// Amount habits are only done once the amounts recorded
+			// reach the target, and show partial progress until then
+			case habit.Amount > 0 && outcome.Result == "y":
+				if SatisfiedByCompletions(d, habit, *entries) {
+					graphDay = symbols.Done
+				} else {
+					graphDay = symbols.Partial
+				}
 			case outcome.Result == "y":
-				graphDay = "━"
+				graphDay = symbols.Done
 			case outcome.Result == "s":
-				graphDay = "•"
+				graphDay = symbols.Skip
 			// Satisfied by genuine completions, but only if the most recent
 			// y/s was a "y" — a skip resets the display to skipified until
 			// a new completion appears.
 			case SatisfiedByCompletions(d, habit, *entries) && !IsInSkipPeriod(d, habit, *entries):
-				graphDay = "─"
+				graphDay = symbols.Satisfied
 			case Skipified(d, habit, *entries):
-				graphDay = "·"
+				graphDay = symbols.Skipified
 			case outcome.Result == "n":
 				graphDay = " "
 			}
 		} else {
 			if Warning(d, habit, *entries) && (to.DaysSince(d) < 14) {
 				// warning: sigils max out at 2 weeks (~90 day habit in formula)
-				graphDay = "!"
-			} else if d.After(habit.FirstRecord) {
+				graphDay = symbols.Warning
+			} else if d.After(habit.FirstRecord) && habit.IsScheduled(d) {
 				// For people who miss days but then put in later ones
-				graphDay = "◌"
+				graphDay = symbols.Unrecorded
 			} else {
 				graphDay = " "
 			}
@@ -64,6 +79,38 @@ func BuildGraph(habit *storage.Habit, entries *storage.Entries, countBack int, a
 	return consistency.String()
 }
 
+// entryOn returns a habit's entry on day d. Paused days with nothing logged
+// count as skipped, as if "s" had been answered for each of them.
+func entryOn(d civil.Date, habit *storage.Habit, entries storage.Entries) (storage.Outcome, bool) {
+	if v, ok := entries[storage.DailyHabit{Day: d, Habit: habit.Name}]; ok {
+		return v, true
+	}
+	if habit.IsPaused(d) {
+		return storage.Outcome{Result: "s"}, true
+	}
+	return storage.Outcome{}, false
+}
+
+// avoidGraphDay picks the graph symbol for day d of an avoidance habit, where
+// days it was not done, logged or not, are the ones that count as done
+func avoidGraphDay(d civil.Date, to civil.Date, habit *storage.Habit, entries storage.Entries) string {
+	outcome, ok := entryOn(d, habit, entries)
+	switch {
+	case habit.FirstRecord.IsZero() || d.Before(habit.FirstRecord):
+		return " "
+	case ok && outcome.Result == "s":
+		return symbols.Skip
+	case OverLimit(d, habit, entries):
+		return " "
+	case ok && outcome.Result == "y":
+		// Done, but within the habit's limit
+		return symbols.Satisfied
+	case Warning(d, habit, entries) && to.DaysSince(d) < 14:
+		return symbols.Warning
+	}
+	return symbols.Done
+}
+
 // Satisfied checks if a habit target is satisfied within its interval window.
 // Counts both "y" and "s" entries as successes -- used for streak calculations
 // where skips maintain streaks.
@@ -80,6 +127,15 @@ func SatisfiedByCompletions(d civil.Date, habit *storage.Habit, entries storage.
 }
 
 func satisfiedImpl(d civil.Date, habit *storage.Habit, entries storage.Entries, countSkips bool) bool {
+	if habit.Avoid {
+		return !OverLimit(d, habit, entries)
+	}
+	if habit.Amount > 0 {
+		return amountSatisfied(d, habit, entries, countSkips)
+	}
+	if habit.Period != "" {
+		return periodSatisfied(d, habit, entries, countSkips)
+	}
 	if habit.Target <= 1 && habit.Interval == 1 {
 		return false
 	}
@@ -108,7 +164,7 @@ func satisfiedImpl(d civil.Date, habit *storage.Habit, entries storage.Entries,
 
 		// Early termination: stop counting once we exceed target
 		for dt := winStart; !dt.After(winEnd) && countTotal < habit.Target+1; dt = dt.AddDays(1) {
-			if v, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok {
+			if v, ok := entryOn(dt, habit, entries); ok {
 				isSuccess := v.Result == "y" || (countSkips && v.Result == "s")
 				if isSuccess {
 					countTotal++
@@ -138,8 +194,76 @@ func satisfiedImpl(d civil.Date, habit *storage.Habit, entries storage.Entries,
 	return false
 }
 
+// amountSatisfied checks if an amount habit's target is met by the amounts
+// summed within any interval-length window that includes d, with the same
+// rule on future data as satisfiedImpl. A skip counts as the whole amount.
+func amountSatisfied(d civil.Date, habit *storage.Habit, entries storage.Entries, countSkips bool) bool {
+	earliestStart := d.AddDays(-habit.Interval + 1)
+	if earliestStart.Before(habit.FirstRecord) {
+		earliestStart = habit.FirstRecord
+	}
+
+	for winStart := earliestStart; !winStart.After(d); winStart = winStart.AddDays(1) {
+		winEnd := winStart.AddDays(habit.Interval - 1)
+		if windowAmount(winStart, winEnd, habit, entries, countSkips) >= habit.Amount &&
+			windowAmount(winStart, d, habit, entries, countSkips) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// windowAmount sums the amounts logged with an amount habit's "y" entries
+// between two days, both included. With countSkips, a skip counts as the
+// whole amount.
+func windowAmount(from civil.Date, to civil.Date, habit *storage.Habit, entries storage.Entries, countSkips bool) float64 {
+	total := 0.0
+	for dt := from; !dt.After(to); dt = dt.AddDays(1) {
+		if v, ok := entryOn(dt, habit, entries); ok {
+			switch {
+			case v.Result == "y":
+				total += v.Amount
+			case countSkips && v.Result == "s":
+				total += habit.Amount
+			}
+		}
+	}
+	return total
+}
+
+// OverLimit checks if an avoidance habit was done once too often on d, with a
+// "y" on d that takes it past its Limit within the interval ending on d
+func OverLimit(d civil.Date, habit *storage.Habit, entries storage.Entries) bool {
+	if !habit.Avoid {
+		return false
+	}
+	if v, ok := entryOn(d, habit, entries); !ok || v.Result != "y" {
+		return false
+	}
+	return countDone(d.AddDays(-habit.Interval+1), d, habit, entries) > habit.Limit
+}
+
+// allowanceUsed checks if an avoidance habit has been done as often as its
+// Limit allows within the interval ending on d, so doing it again now would
+// break the streak. Habits never allowed are not counted as used up.
+func allowanceUsed(d civil.Date, habit *storage.Habit, entries storage.Entries) bool {
+	return habit.Limit > 0 && countDone(d.AddDays(-habit.Interval+1), d, habit, entries) >= habit.Limit
+}
+
+// countDone counts a habit's "y" entries between two days, both included
+func countDone(from civil.Date, to civil.Date, habit *storage.Habit, entries storage.Entries) int {
+	count := 0
+	for dt := from; !dt.After(to); dt = dt.AddDays(1) {
+		if v, ok := entryOn(dt, habit, entries); ok && v.Result == "y" {
+			count++
+		}
+	}
+	return count
+}
+
 // Skipified checks if a habit has been skipped within its grace period.
-// The grace period is the full interval, matching the window that Satisfied uses.
+// The grace period is the full interval, matching the window that Satisfied uses,
+// or the calendar period so far for calendar period habits.
 func Skipified(d civil.Date, habit *storage.Habit, entries storage.Entries) bool {
 	if habit.Target <= 1 && habit.Interval == 1 {
 		return false
@@ -147,8 +271,11 @@ func Skipified(d civil.Date, habit *storage.Habit, entries storage.Entries) bool
 
 	from := d
 	to := d.AddDays(-(habit.Interval - 1))
+	if habit.Period != "" {
+		to, _ = PeriodBounds(d, habit)
+	}
 	for dt := from; !dt.Before(to); dt = dt.AddDays(-1) {
-		if v, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok {
+		if v, ok := entryOn(dt, habit, entries); ok {
 			if v.Result == "s" {
 				return true
 			}
@@ -162,13 +289,37 @@ func Warning(d civil.Date, habit *storage.Habit, entries storage.Entries) bool {
 	if habit.Target < 1 {
 		return false
 	}
+	// Weekday habits are only at risk on the days they are due
+	if !habit.IsScheduled(d) {
+		return false
+	}
 
 	warningDays := int(habit.Interval)/7 + 1
 	to := d
 	from := d.AddDays(-int(habit.Interval) + warningDays)
 	noFirstRecord := civil.Date{Year: 0, Month: 0, Day: 0}
+	// Avoidance habits warn once their allowance is used up
+	if habit.Avoid {
+		if habit.FirstRecord == noFirstRecord || d.Before(habit.FirstRecord) {
+			return false
+		}
+		return allowanceUsed(d, habit, entries)
+	}
+	// Amount habits warn while the window ending warningDays-1 days after d
+	// holds less than the target amount so far, as the habits below warn
+	// while it holds no "y" or "s"
+	if habit.Amount > 0 {
+		if habit.FirstRecord == noFirstRecord || from.Before(habit.FirstRecord) {
+			return false
+		}
+		return windowAmount(from, to, habit, entries, true) < habit.Amount
+	}
+	// Calendar period habits warn as the end of the period nears
+	if habit.Period != "" {
+		return periodWarning(d, habit, entries)
+	}
 	for dt := from; !dt.After(to); dt = dt.AddDays(1) {
-		if v, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok {
+		if v, ok := entryOn(dt, habit, entries); ok {
 			switch v.Result {
 			case "y":
 				return false
@@ -188,7 +339,8 @@ func Warning(d civil.Date, habit *storage.Habit, entries storage.Entries) bool {
 }
 
 // DaysUntilStreakBreak calculates how many days until a habit's streak will break
-// Returns -1 if the habit doesn't have a streak or is a tracking-only habit (target 0)
+// Returns -1 if the habit doesn't have a streak or is a tracking-only habit (target 0),
+// or is an avoidance habit with some of its allowance left
 func DaysUntilStreakBreak(d civil.Date, habit *storage.Habit, entries storage.Entries) int {
 	// Tracking-only habits (target 0) don't have streaks
 	if habit.Target < 1 {
@@ -201,6 +353,33 @@ func DaysUntilStreakBreak(d civil.Date, habit *storage.Habit, entries storage.En
 		return -1
 	}
 
+	// Weekday habits (e.g., mon,wed,fri) break on a due day not done
+	if len(habit.Weekdays) > 0 {
+		return daysUntilStreakBreakWeekdays(d, habit, entries)
+	}
+
+	// Avoidance habits (e.g., !, !2/7) only break when done over their limit,
+	// so have no break date until their allowance is used up
+	if habit.Avoid {
+		switch {
+		case OverLimit(d, habit, entries):
+			return -999
+		case allowanceUsed(d, habit, entries):
+			return 0
+		}
+		return -1
+	}
+
+	// Amount habits (e.g., 8 glasses/1, 20km/7) depend on the amounts in the window
+	if habit.Amount > 0 {
+		return daysUntilStreakBreakAmount(d, habit, entries)
+	}
+
+	// Calendar period habits (e.g., 2/month, 1/week) break at the end of a period not met
+	if habit.Period != "" {
+		return daysUntilStreakBreakPeriod(d, habit, entries)
+	}
+
 	// For habits with Target=1 (e.g., 1/1, 1/7, 1/90), use the simpler direct approach
 	// Only use windowing for multi-target habits (e.g., 3/7, 2/14)
 	if habit.Target == 1 {
@@ -223,7 +402,7 @@ func daysUntilStreakBreakSimple(d civil.Date, habit *storage.Habit, entries stor
 	lastSuccessDate := civil.Date{Year: 0, Month: 0, Day: 0}
 	lastSuccessResult := ""
 	for dt := d; !dt.Before(lookbackStart); dt = dt.AddDays(-1) {
-		if v, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok {
+		if v, ok := entryOn(dt, habit, entries); ok {
 			if v.Result == "y" || v.Result == "s" {
 				lastSuccessDate = dt
 				lastSuccessResult = v.Result
@@ -248,7 +427,7 @@ func daysUntilStreakBreakSimple(d civil.Date, habit *storage.Habit, entries stor
 
 		priorSuccessDate := civil.Date{Year: 0, Month: 0, Day: 0}
 		for dt := lastSuccessDate.AddDays(-1); !dt.Before(priorLookbackStart); dt = dt.AddDays(-1) {
-			if v, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok {
+			if v, ok := entryOn(dt, habit, entries); ok {
 				if v.Result == "y" || v.Result == "s" {
 					priorSuccessDate = dt
 					break
@@ -307,7 +486,7 @@ func daysUntilStreakBreakWindowed(d civil.Date, habit *storage.Habit, entries st
 		var firstSuccessInWindow civil.Date
 
 		for dt := winStart; !dt.After(d) && !dt.After(winEnd); dt = dt.AddDays(1) {
-			if v, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok && (v.Result == "y" || v.Result == "s") {
+			if v, ok := entryOn(dt, habit, entries); ok && (v.Result == "y" || v.Result == "s") {
 				successCount++
 				if firstSuccessInWindow.Year == 0 {
 					firstSuccessInWindow = dt
@@ -333,11 +512,62 @@ func daysUntilStreakBreakWindowed(d civil.Date, habit *storage.Habit, entries st
 	return streakBreakDate.DaysSince(d)
 }
 
+// daysUntilStreakBreakWeekdays handles weekday habits (mon,wed,fri, tue, etc.)
+func daysUntilStreakBreakWeekdays(d civil.Date, habit *storage.Habit, entries storage.Entries) int {
+	done := func(dt civil.Date) bool {
+		v, ok := entryOn(dt, habit, entries)
+		return ok && (v.Result == "y" || v.Result == "s")
+	}
+
+	// The streak is broken if the last due day before today was missed
+	for dt := d.AddDays(-1); !dt.Before(habit.FirstRecord) && !dt.Before(d.AddDays(-7)); dt = dt.AddDays(-1) {
+		if habit.IsScheduled(dt) {
+			if !done(dt) {
+				return -999
+			}
+			break
+		}
+	}
+
+	// Due today and not yet done - last chance to maintain it
+	if habit.IsScheduled(d) && !done(d) {
+		return 0
+	}
+
+	// Otherwise it breaks if the next due day is missed
+	next := d.AddDays(1)
+	for !habit.IsScheduled(next) {
+		next = next.AddDays(1)
+	}
+	return next.DaysSince(d)
+}
+
+// daysUntilStreakBreakAmount handles amount habits (8 glasses/1, 20km/7, etc.)
+func daysUntilStreakBreakAmount(d civil.Date, habit *storage.Habit, entries storage.Entries) int {
+	if !Satisfied(d, habit, entries) {
+		yesterday := d.AddDays(-1)
+		if !yesterday.Before(habit.FirstRecord) && Satisfied(yesterday, habit, entries) {
+			return 0
+		}
+		return -999
+	}
+
+	// With nothing more recorded, a later day stays satisfied while the
+	// window ending on it still holds the target amount
+	for days := 1; days < habit.Interval; days++ {
+		winStart := d.AddDays(days - habit.Interval + 1)
+		if windowAmount(winStart, d, habit, entries, true) < habit.Amount {
+			return days
+		}
+	}
+	return habit.Interval
+}
+
 // IsInSkipPeriod checks if a habit's most recent entry (within the interval) was a skip
 // This is used to show a distinct indicator for habits in a skip grace period
 func IsInSkipPeriod(d civil.Date, habit *storage.Habit, entries storage.Entries) bool {
-	// Tracking-only habits don't have skip periods
-	if habit.Target < 1 {
+	// Tracking-only and avoidance habits don't have skip periods
+	if habit.Target < 1 || habit.Avoid {
 		return false
 	}
 
@@ -355,7 +585,7 @@ func IsInSkipPeriod(d civil.Date, habit *storage.Habit, entries storage.Entries)
 
 	// Find the most recent "y" or "s" entry
 	for dt := d; !dt.Before(lookbackStart); dt = dt.AddDays(-1) {
-		if v, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok {
+		if v, ok := entryOn(dt, habit, entries); ok {
 			if v.Result == "s" {
 				return true
 			}
@@ -395,8 +625,30 @@ func StreakLengths(d civil.Date, habit *storage.Habit, entries storage.Entries)
 			continue
 		}
 
-		if outcome, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok {
+		// Paused days with nothing logged neither build nor break the streak
+		if _, ok := entries[storage.DailyHabit{Day: dt, Habit: habit.Name}]; !ok && habit.IsPaused(dt) {
+			continue
+		}
+
+		// Avoidance habits build their streak on every day not done over the limit
+		if habit.Avoid {
+			if OverLimit(dt, habit, entries) {
+				currentRun = 0
+			} else {
+				currentRun++
+			}
+			longestRun = max(longestRun, currentRun)
+			continue
+		}
+
+		if outcome, ok := entryOn(dt, habit, entries); ok {
 			switch {
+			case habit.Amount > 0 && outcome.Result == "y":
+				if Satisfied(dt, habit, entries) {
+					currentRun++
+				} else if Warning(dt, habit, entries) {
+					currentRun = 0
+				}
 			case outcome.Result == "y":
 				currentRun++
 			case outcome.Result == "s":
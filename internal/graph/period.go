@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/wakatara/harsh/internal/storage"
+)
+
+// weekStart is the day calendar weeks start on
+var weekStart = time.Monday
+
+// UseWeekStart sets the day calendar weeks start on for habits counted per
+// week, "monday" or "sunday"
+func UseWeekStart(name string) error {
+	switch name {
+	case "monday":
+		weekStart = time.Monday
+	case "sunday":
+		weekStart = time.Sunday
+	default:
+		return fmt.Errorf("unknown week start %q (expected monday or sunday)", name)
+	}
+	return nil
+}
+
+// PeriodBounds returns the first and last day of the calendar week, month or
+// quarter holding d that a calendar period habit counts its target in
+func PeriodBounds(d civil.Date, habit *storage.Habit) (civil.Date, civil.Date) {
+	switch habit.Period {
+	case "week":
+		from := d.AddDays(-((int(d.Weekday()) - int(weekStart) + 7) % 7))
+		return from, from.AddDays(6)
+	case "quarter":
+		from := civil.Date{Year: d.Year, Month: (d.Month-1)/3*3 + 1, Day: 1}
+		return from, from.AddMonths(3).AddDays(-1)
+	}
+	from := civil.Date{Year: d.Year, Month: d.Month, Day: 1}
+	return from, from.AddMonths(1).AddDays(-1)
+}
+
+// periodSatisfied checks if a calendar period habit's target is met by the
+// successes within the period holding d, with the same rule on future data
+// as satisfiedImpl
+func periodSatisfied(d civil.Date, habit *storage.Habit, entries storage.Entries, countSkips bool) bool {
+	from, to := PeriodBounds(d, habit)
+	return countSuccesses(from, d, habit, entries, countSkips) > 0 &&
+		countSuccesses(from, to, habit, entries, countSkips) >= habit.Target
+}
+
+// periodWarning checks if the days left in the calendar period holding d are
+// running out for the successes a period habit still needs. The period the
+// habit was first recorded in is not warned about, as it was only partly tracked.
+func periodWarning(d civil.Date, habit *storage.Habit, entries storage.Entries) bool {
+	from, to := PeriodBounds(d, habit)
+	if habit.FirstRecord.IsZero() || from.Before(habit.FirstRecord) {
+		return false
+	}
+	needed := habit.Target - countSuccesses(from, d, habit, entries, true)
+	warningDays := habit.Interval/7 + 1
+	return needed > 0 && to.DaysSince(d) < needed+warningDays-1
+}
+
+// daysUntilStreakBreakPeriod handles calendar period habits (2/month, 1/week, etc.)
+func daysUntilStreakBreakPeriod(d civil.Date, habit *storage.Habit, entries storage.Entries) int {
+	from, to := PeriodBounds(d, habit)
+
+	// Met this period - it breaks if the next period is not met
+	if countSuccesses(from, d, habit, entries, true) >= habit.Target {
+		_, nextTo := PeriodBounds(to.AddDays(1), habit)
+		return nextTo.DaysSince(d)
+	}
+
+	// The streak is broken if the last full period was not met
+	lastFrom, lastTo := PeriodBounds(from.AddDays(-1), habit)
+	if !lastFrom.Before(habit.FirstRecord) && countSuccesses(lastFrom, lastTo, habit, entries, true) < habit.Target {
+		return -999
+	}
+
+	// Otherwise it breaks at the end of this period
+	return to.DaysSince(d)
+}
+
+// countSuccesses counts a habit's "y" entries between two days, both
+// included, and its "s" entries too with countSkips
+func countSuccesses(from civil.Date, to civil.Date, habit *storage.Habit, entries storage.Entries, countSkips bool) int {
+	count := 0
+	for dt := from; !dt.After(to); dt = dt.AddDays(1) {
+		if v, ok := entryOn(dt, habit, entries); ok && (v.Result == "y" || (countSkips && v.Result == "s")) {
+			count++
+		}
+	}
+	return count
+}
@@ -12,7 +12,7 @@ import (
 func BuildSpark(from civil.Date, to civil.Date, habits []*storage.Habit, entries *storage.Entries) ([]string, []string) {
 	sparkline := []string{}
 	calline := []string{}
-	sparks := []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+	sparks := symbols.Sparks
 	i := 0
 	LetterDay := map[string]string{
 		"Sunday": " ", "Monday": "M", "Tuesday": " ", "Wednesday": "W",
@@ -46,10 +46,10 @@ func BuildSpark(from civil.Date, to civil.Date, habits []*storage.Habit, entries
 			if prevWeekday == "Monday" || prevWeekday == "Wednesday" || prevWeekday == "Friday" {
 				// Previous day shows a letter (M/W/F), so put left-aligned marker on current day
 				// Current day is always a space (Tue after Mon, Thu after Wed, Sat after Fri)
-				calline = append(calline, "▏")
+				calline = append(calline, symbols.MonthLeft)
 			} else {
 				// Previous day is a space (Sat/Sun/Tue/Thu), so put right-aligned marker there
-				calline[len(calline)-1] = "▕"
+				calline[len(calline)-1] = symbols.MonthRight
 				calline = append(calline, LetterDay[w])
 			}
 		} else {
@@ -68,18 +68,41 @@ func BuildSpark(from civil.Date, to civil.Date, habits []*storage.Habit, entries
 }
 
 // Score calculates the daily score for a given date
-// Excludes habits that have ended (after their EndRecord date)
+// Excludes habits that have not started (before their StartRecord date) or
+// have ended (after their EndRecord date)
 func Score(d civil.Date, habits []*storage.Habit, entries *storage.Entries) float64 {
 	scored := 0.0
 	skipped := 0.0
 	scorableHabits := 0.0
 
 	for _, habit := range habits {
+		// Weekday habits are only scored on their days off if logged
+		if _, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]; !ok && !habit.IsScheduled(d) {
+			continue
+		}
 		// Only score habits that are active on this date (started and not ended)
-		if habit.Target > 0 && !d.Before(habit.FirstRecord) && !habit.HasEnded(d) {
+		if habit.Target > 0 && habit.HasStarted(d) && !d.Before(habit.FirstRecord) && !habit.HasEnded(d) {
+			// Avoidance habits score on every day not done over their limit
+			if habit.Avoid {
+				if habit.FirstRecord.IsZero() {
+					continue
+				}
+				scorableHabits++
+				if outcome, ok := entryOn(d, habit, *entries); ok && outcome.Result == "s" {
+					skipped++
+				} else if !OverLimit(d, habit, *entries) {
+					scored++
+				}
+				continue
+			}
 			scorableHabits++
-			if outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]; ok {
+			if outcome, ok := entryOn(d, habit, *entries); ok {
 				switch {
+				// Amount habits only score once the target amount is met
+				case habit.Amount > 0 && outcome.Result == "y":
+					if SatisfiedByCompletions(d, habit, *entries) {
+						scored++
+					}
 				case outcome.Result == "y":
 					scored++
 				case outcome.Result == "s":
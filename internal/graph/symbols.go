@@ -0,0 +1,50 @@
+package graph
+
+import "fmt"
+
+// Symbols are the characters graphs and sparklines are drawn with
+type Symbols struct {
+	Done       string    // Habit done ("y")
+	Skip       string    // Habit skipped ("s")
+	Partial    string    // Some of an amount habit's target recorded
+	Satisfied  string    // Target already met within the interval
+	Skipified  string    // Covered by a recent skip
+	Warning    string    // Streak about to break
+	Unrecorded string    // Nothing logged since the first record
+	End        string    // Day after the habit's end date
+	NotStarted string    // Day before the habit's start date
+	Paused     string    // Day the habit is paused, with nothing logged
+	Sparks     [9]string // Sparkline levels, from no score to a perfect one
+	MonthLeft  string    // Month boundary following a M/W/F day letter
+	MonthRight string    // Month boundary replacing a blank day
+}
+
+// symbolSets are the symbol sets graphs can be drawn with, by name
+var symbolSets = map[string]Symbols{
+	"unicode": {
+		Done: "━", Skip: "•", Partial: "╍", Satisfied: "─", Skipified: "·",
+		Warning: "!", Unrecorded: "◌", End: "▏", NotStarted: "┈", Paused: "‖",
+		Sparks:    [9]string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"},
+		MonthLeft: "▏", MonthRight: "▕",
+	},
+	"ascii": {
+		Done: "=", Skip: "*", Partial: "~", Satisfied: "-", Skipified: ".",
+		Warning: "!", Unrecorded: "o", End: "|", NotStarted: "_", Paused: ":",
+		Sparks:    [9]string{" ", ".", ":", "-", "=", "+", "*", "#", "@"},
+		MonthLeft: "|", MonthRight: "|",
+	},
+}
+
+// symbols is the symbol set in use
+var symbols = symbolSets["unicode"]
+
+// UseSymbols sets the symbol set graphs and sparklines are drawn with,
+// "unicode" or "ascii"
+func UseSymbols(name string) error {
+	set, ok := symbolSets[name]
+	if !ok {
+		return fmt.Errorf("unknown symbol set %q (expected unicode or ascii)", name)
+	}
+	symbols = set
+	return nil
+}
@@ -16,33 +16,93 @@ type Harsh struct {
 	MaxHabitNameLength int
 	CountBack          int
 	Entries            *storage.Entries
+	Warnings           []*storage.ParseError
+	FirstRun           bool
+	Settings           *storage.Settings
 }
 
-// NewHarsh creates a new Harsh instance with loaded configuration and data
-func NewHarsh() *Harsh {
-	repository := storage.NewFileRepository()
-	habits, maxHabitNameLength, _ := repository.LoadHabits()
-	entries, _ := repository.LoadEntries()
-	
+// NewHarsh creates a new Harsh instance with the configuration and data in
+// paths loaded, run with the given settings
+func NewHarsh(paths storage.Paths, settings *storage.Settings) (*Harsh, error) {
+	repository, err := storage.NewFileRepository(paths)
+	if err != nil {
+		return nil, err
+	}
+	habits, maxHabitNameLength, err := repository.LoadHabits()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := repository.LoadEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	// Yearly archives are only read when a command needs older entries, but
+	// todo and ask still need every habit's current window to be complete
 	now := civil.DateOf(time.Now())
+	longestInterval := 1
+	for _, habit := range habits {
+		longestInterval = max(longestInterval, habit.Interval)
+	}
+	warnings, err := repository.LoadHistory(now.AddDays(-longestInterval - 30))
+	if err != nil {
+		return nil, err
+	}
+
 	to := now
 	from := to.AddDays(-365 * 5)
 	entries.FirstRecords(from, to, habits)
-	
+
 	width, _, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
 		// Default width for testing or when terminal size cannot be determined
 		width = 120
 	}
 	countBack := max(1, min(width-maxHabitNameLength-2, 100))
-	
+	if settings.GraphDays > 0 {
+		countBack = settings.GraphDays
+	}
+
 	return &Harsh{
 		Repository:         repository,
 		Habits:             habits,
 		MaxHabitNameLength: maxHabitNameLength,
 		CountBack:          countBack,
 		Entries:            entries,
+		Warnings:           append(repository.Warnings(), warnings...),
+		FirstRun:           repository.Created(),
+		Settings:           settings,
+	}, nil
+}
+
+// LoadHistory adds archived entries from the given date onwards, for commands
+// that look further back than the current log, and returns any warnings from
+// reading the archives. A zero from loads the whole history.
+func (h *Harsh) LoadHistory(from civil.Date) ([]*storage.ParseError, error) {
+	warnings, err := h.Repository.LoadHistory(from)
+	if err != nil {
+		return warnings, err
 	}
+
+	// Archived entries may start habits earlier than the current log did
+	to := civil.DateOf(time.Now())
+	first := to.AddDays(-365 * 5)
+	for key := range *h.Entries {
+		if key.Day.Before(first) {
+			first = key.Day
+		}
+	}
+	h.Entries.FirstRecords(first, to, h.Habits)
+	return warnings, nil
+}
+
+// GetTaggedHabits returns the habits tagged with any of tags, or all of them
+// when no tags are given
+func (h *Harsh) GetTaggedHabits(tags []string) []*storage.Habit {
+	if len(tags) == 0 {
+		return h.Habits
+	}
+	return storage.FilterHabitsByTag(h.Habits, tags)
 }
 
 // GetRepository returns the repository instance
@@ -65,7 +125,17 @@ func (h *Harsh) GetMaxHabitNameLength() int {
 	return h.MaxHabitNameLength
 }
 
+// GetWarnings returns the malformed lines skipped while loading habits and log
+func (h *Harsh) GetWarnings() []*storage.ParseError {
+	return h.Warnings
+}
+
 // GetCountBack returns the count back value for graph length
 func (h *Harsh) GetCountBack() int {
 	return h.CountBack
 }
+
+// GetSettings returns the settings harsh is running with
+func (h *Harsh) GetSettings() *storage.Settings {
+	return h.Settings
+}
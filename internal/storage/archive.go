@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/civil"
+)
+
+// archivePattern matches yearly log archives, e.g. "log.2023" or "log.2023.gz"
+var archivePattern = regexp.MustCompile(`^log\.(\d{4})(\.gz)?$`)
+
+// ArchiveResult describes the entries ArchiveLog moves, or would move, out of the log
+type ArchiveResult struct {
+	Moved int            // Entries moved out of the log
+	Files map[int]string // Archive written for each year
+}
+
+// FindArchives returns the yearly log archives in logDir by year. A year
+// with both a plain and a gzipped archive has both, the plain one first.
+func FindArchives(logDir string) (map[int][]string, error) {
+	files, err := os.ReadDir(logDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// ReadDir sorts by name, so "log.2023" comes before "log.2023.gz"
+	archives := map[int][]string{}
+	for _, f := range files {
+		m := archivePattern.FindStringSubmatch(f.Name())
+		if m == nil || f.IsDir() {
+			continue
+		}
+		year, _ := strconv.Atoi(m[1])
+		archives[year] = append(archives[year], filepath.Join(logDir, f.Name()))
+	}
+	return archives, nil
+}
+
+// LoadArchive reads entries from a yearly log archive, gzipped or not
+func LoadArchive(archivePath string) (*Entries, []*ParseError, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open log archive %s: %w", archivePath, err)
+	}
+	defer file.Close()
+
+	r, err := archiveReader(file, archivePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return readLog(r, archivePath)
+}
+
+// archiveReader decompresses a gzipped archive as it is read
+func archiveReader(file io.Reader, archivePath string) (io.Reader, error) {
+	if !strings.HasSuffix(archivePath, ".gz") {
+		return file, nil
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read gzipped log archive %s: %w", archivePath, err)
+	}
+	return gz, nil
+}
+
+// readArchiveFile returns the uncompressed content of a yearly log archive
+func readArchiveFile(archivePath string) ([]byte, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open log archive %s: %w", archivePath, err)
+	}
+	defer file.Close()
+
+	r, err := archiveReader(file, archivePath)
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading log archive %s: %w", archivePath, err)
+	}
+	return content, nil
+}
+
+// writeArchiveFile atomically replaces a yearly log archive with content,
+// compressing it again if the archive is gzipped
+func writeArchiveFile(archivePath string, content []byte) error {
+	if strings.HasSuffix(archivePath, ".gz") {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(content); err != nil {
+			return fmt.Errorf("failed to compress log archive %s: %w", archivePath, err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to compress log archive %s: %w", archivePath, err)
+		}
+		content = buf.Bytes()
+	}
+	return replaceFile(archivePath, content)
+}
+
+// ArchiveLog moves log entries dated before the given date into yearly
+// archives next to the log, log.YYYY or, with compress set, log.YYYY.gz.
+// Entries are appended to an archive that already exists for the year, in
+// whichever form it has. Comments and lines LoadLog cannot read stay in the
+// log. With dryRun set nothing is written.
+func ArchiveLog(logDir string, before civil.Date, compress bool, dryRun bool) (*ArchiveResult, error) {
+	unlock, err := LockConfigDir(logDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	fileName := filepath.Join(logDir, "log")
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, fmt.Errorf("permission denied reading log file: %s (check file permissions)", fileName)
+		}
+		return nil, fmt.Errorf("cannot read log file %s: %w", fileName, err)
+	}
+
+	existing, err := FindArchives(logDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ArchiveResult{Files: map[int]string{}}
+	byYear := map[int]*strings.Builder{}
+	var kept strings.Builder
+	kept.Grow(len(content))
+
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		key, ok := logLineKey(strings.TrimRight(line, "\r\n"))
+		if !ok || !key.Day.Before(before) {
+			kept.WriteString(line)
+			continue
+		}
+		year := key.Day.Year
+		if byYear[year] == nil {
+			byYear[year] = &strings.Builder{}
+		}
+		byYear[year].WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			byYear[year].WriteString("\n")
+		}
+		result.Moved++
+	}
+
+	years := make([]int, 0, len(byYear))
+	for year := range byYear {
+		years = append(years, year)
+		var archivePath string
+		if paths := existing[year]; len(paths) > 0 {
+			archivePath = paths[0]
+		} else {
+			archivePath = filepath.Join(logDir, "log."+strconv.Itoa(year))
+			if compress {
+				archivePath += ".gz"
+			}
+		}
+		result.Files[year] = archivePath
+	}
+	if dryRun || result.Moved == 0 {
+		return result, nil
+	}
+
+	// Write the archives before removing entries from the log, so an
+	// interrupted archive leaves entries in both places rather than neither
+	sort.Ints(years)
+	for _, year := range years {
+		if err := appendArchive(result.Files[year], byYear[year].String()); err != nil {
+			return nil, err
+		}
+	}
+	if err := replaceFile(fileName, []byte(kept.String())); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// appendArchive appends log lines to an archive. A gzipped archive gets a new
+// gzip member, which readers decompress as one continuous stream.
+func appendArchive(archivePath string, lines string) error {
+	f, err := os.OpenFile(archivePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open log archive %s: %w", archivePath, err)
+	}
+
+	var w io.Writer = f
+	var gz *gzip.Writer
+	if strings.HasSuffix(archivePath, ".gz") {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+	if _, err := io.WriteString(w, lines); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write log archive %s: %w", archivePath, err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write log archive %s: %w", archivePath, err)
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write log archive %s: %w", archivePath, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close log archive %s: %w", archivePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// habitAttributes are the keys a habit line's "[key=value; ...]" block takes
+var habitAttributes = []string{"unit", "tags", "desc", "pause"}
+
+// splitHabitAttributes removes a trailing "[key=value; ...]" attribute block
+// from a habit line, e.g. `Run: 3/7 [unit=km; tags=health,cardio; desc="Zone 2 only"]`,
+// returning the line without it and the attributes in the order given.
+// Values are bare text, or quoted with `\"` and `\\` escapes to hold ";" or "]".
+// A malformed block is still removed, and reported as an error.
+func splitHabitAttributes(line string) (string, [][2]string, error) {
+	trimmed := strings.TrimRight(line, " \t")
+	sep := strings.Index(trimmed, ": ")
+	if sep == -1 {
+		return line, nil, nil
+	}
+	open := strings.Index(trimmed[sep:], "[")
+	if open == -1 {
+		return line, nil, nil
+	}
+	open += sep
+	rest := strings.TrimRight(trimmed[:open], " \t")
+	if !strings.HasSuffix(trimmed, "]") {
+		return rest, nil, fmt.Errorf("malformed attributes, missing the closing ]")
+	}
+	attrs, err := parseHabitAttributes(trimmed[open+1 : len(trimmed)-1])
+	return rest, attrs, err
+}
+
+// parseHabitAttributes parses the key=value pairs between the brackets of an
+// attribute block
+func parseHabitAttributes(block string) ([][2]string, error) {
+	var attrs [][2]string
+	rest := block
+	for strings.TrimSpace(rest) != "" {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			return attrs, fmt.Errorf("malformed attributes (expected format: [key=value; key=value])")
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimLeft(value, " \t")
+
+		if strings.HasPrefix(value, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) {
+					i++
+				}
+				b.WriteByte(value[i])
+			}
+			if i == len(value) {
+				return attrs, fmt.Errorf("unterminated quoted value for attribute %q", key)
+			}
+			rest = strings.TrimLeft(value[i+1:], " \t")
+			if rest != "" && rest[0] != ';' {
+				return attrs, fmt.Errorf("malformed attributes, expected ; after the value of %q", key)
+			}
+			value = b.String()
+		} else {
+			value, rest, _ = strings.Cut(value, ";")
+			value = strings.TrimSpace(value)
+			if strings.ContainsAny(value, `]"`) {
+				return attrs, fmt.Errorf("malformed value for attribute %q (quote values holding ] or \")", key)
+			}
+		}
+		rest = strings.TrimPrefix(rest, ";")
+		attrs = append(attrs, [2]string{key, value})
+	}
+	return attrs, nil
+}
+
+// applyAttributes sets the habit's unit, tags, description and pauses from
+// the attributes of its habit line, returning an error naming any unknown key
+// or invalid pause after setting the rest
+func (h *Habit) applyAttributes(attrs [][2]string) error {
+	var unknown []string
+	var pauseErr error
+	for _, attr := range attrs {
+		switch attr[0] {
+		case "unit":
+			h.Unit = attr[1]
+		case "tags":
+			h.Tags = nil
+			for _, tag := range strings.Split(attr[1], ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					h.Tags = append(h.Tags, tag)
+				}
+			}
+		case "desc":
+			h.Description = attr[1]
+		case "pause":
+			var pauses []Pause
+			pauses, pauseErr = parsePauses(attr[1])
+			h.Pauses = append(h.Pauses, pauses...)
+		default:
+			unknown = append(unknown, attr[0])
+		}
+	}
+	if len(unknown) > 0 {
+		return errors.Join(fmt.Errorf("ignoring unknown attributes %s (expected %s)", strings.Join(unknown, ", "), strings.Join(habitAttributes, ", ")), pauseErr)
+	}
+	return pauseErr
+}
+
+// formatHabitAttributes renders the habit's unit, tags and description as an
+// attribute block, or an empty string if it has none
+func formatHabitAttributes(h *Habit) string {
+	var attrs []string
+	if h.Unit != "" && !frequencyHasUnit(h) {
+		attrs = append(attrs, "unit="+formatAttributeValue(h.Unit))
+	}
+	if len(h.Tags) > 0 {
+		attrs = append(attrs, "tags="+formatAttributeValue(strings.Join(h.Tags, ",")))
+	}
+	if h.Description != "" {
+		attrs = append(attrs, `desc="`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(h.Description)+`"`)
+	}
+	if len(h.Pauses) > 0 {
+		pauses := make([]string, len(h.Pauses))
+		for i, pause := range h.Pauses {
+			pauses[i] = pause.String()
+		}
+		attrs = append(attrs, "pause="+strings.Join(pauses, ","))
+	}
+	if len(attrs) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(attrs, "; ") + "]"
+}
+
+// frequencyHasUnit returns true if the habit's unit is already given by its
+// amount frequency, e.g. "glasses" in "8 glasses/1"
+func frequencyHasUnit(h *Habit) bool {
+	target, _, slash := strings.Cut(h.Frequency, "/")
+	_, unit, ok := parseAmount(strings.TrimSpace(target))
+	return slash && ok && unit == h.Unit
+}
+
+// formatAttributeValue quotes an attribute value where it could not be read
+// back bare
+func formatAttributeValue(v string) string {
+	if strings.ContainsAny(v, `;]"\`) || strings.TrimSpace(v) != v {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+	}
+	return v
+}
+
+// HasTag returns true if the habit is tagged with tag, ignoring case
+func (h *Habit) HasTag(tag string) bool {
+	return slices.ContainsFunc(h.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// FilterHabitsByTag returns the habits tagged with any of tags
+func FilterHabitsByTag(habits []*Habit, tags []string) []*Habit {
+	var filtered []*Habit
+	for _, habit := range habits {
+		if slices.ContainsFunc(tags, habit.HasTag) {
+			filtered = append(filtered, habit)
+		}
+	}
+	return filtered
+}
@@ -3,12 +3,14 @@ package storage
 import (
 	"bufio"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"path/filepath"
-	"runtime"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/civil"
 )
@@ -20,8 +22,59 @@ type Habit struct {
 	Frequency   string
 	Target      int
 	Interval    int
+	Amount      float64        // Amount to record within the interval, for amount habits
+	Avoid       bool           // Doing the habit is the failure, beyond Limit times per interval
+	Limit       int            // Times an avoidance habit is allowed within the interval
+	Weekdays    []time.Weekday // Days of the week a weekday habit is due, or none for every day
+	Period      string         // Calendar period the target is counted in, or empty for a rolling interval
 	FirstRecord civil.Date
+	StartRecord civil.Date // Optional start date - habit not tracked before this date
 	EndRecord   civil.Date // Optional end date - habit retired after this date
+	Pauses      []Pause    // Days the habit is paused, from its pause attribute
+	GroupPauses []Pause    // Days all habits, or those under its heading, are paused
+	Aliases     []string   // Former names the habit was logged under
+	Unit        string     // Unit amounts are recorded in, e.g. "km"
+	Tags        []string   // Tags to filter habits by
+	Description string     // What the habit involves, shown when asking
+}
+
+// habitAliasPattern matches a habit line declaring former names,
+// e.g. "Gym (was: Gymmed, Gym time): 3/7"
+var habitAliasPattern = regexp.MustCompile(`^(.*?)\s*\(was:\s*([^)]*)\)\s*(:.*)$`)
+
+// splitHabitAliases removes a "(was: ...)" former names declaration from a
+// habit line, returning the line without it and the declared names
+func splitHabitAliases(line string) (string, []string) {
+	m := habitAliasPattern.FindStringSubmatch(line)
+	if m == nil {
+		return line, nil
+	}
+	var aliases []string
+	for _, alias := range strings.Split(m[2], ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, UnescapeField(alias))
+		}
+	}
+	return m[1] + m[3], aliases
+}
+
+// Names returns the habit name followed by its former names
+func (h *Habit) Names() []string {
+	return append([]string{h.Name}, h.Aliases...)
+}
+
+// HasStarted returns true unless the habit has a start date and the given date is before it
+func (h *Habit) HasStarted(d civil.Date) bool {
+	return h.StartRecord.IsZero() || !d.Before(h.StartRecord)
+}
+
+// IsScheduled returns true if the habit is due on the given date, which is
+// every day unless it is scheduled on certain weekdays
+func (h *Habit) IsScheduled(d civil.Date) bool {
+	if len(h.Weekdays) == 0 {
+		return true
+	}
+	return slices.Contains(h.Weekdays, d.In(time.UTC).Weekday())
 }
 
 // HasEnded returns true if the habit has an end date and the given date is after it
@@ -37,6 +90,46 @@ func (h *Habit) IsEnded() bool {
 	return !h.EndRecord.IsZero()
 }
 
+// FindHabit resolves name to a single habit from the habits file.
+// An exact, case-insensitive name match wins; otherwise name must be a
+// fragment of exactly one habit. A former name declared with "(was: ...)"
+// also matches exactly. Unknown and ambiguous names are errors.
+func FindHabit(habits []*Habit, name string) (*Habit, error) {
+	needle := strings.ToLower(strings.TrimSpace(name))
+	if needle == "" {
+		return nil, fmt.Errorf("no habit name given")
+	}
+
+	var matches []*Habit
+	for _, habit := range habits {
+		habitName := strings.ToLower(habit.Name)
+		if habitName == needle {
+			return habit, nil
+		}
+		for _, alias := range habit.Aliases {
+			if strings.ToLower(alias) == needle {
+				return habit, nil
+			}
+		}
+		if strings.Contains(habitName, needle) {
+			matches = append(matches, habit)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no habit matches %q in your habits file", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, habit := range matches {
+			names[i] = habit.Name
+		}
+		return nil, fmt.Errorf("%q is ambiguous, it matches: %s", name, strings.Join(names, ", "))
+	}
+}
+
 const DEFAULT_HABITS = 
 `# This is your habits file.
 # It tells harsh what to track and how frequently.
@@ -44,6 +137,8 @@ const DEFAULT_HABITS =
 # You can also track targets within a set number of days.
 # For example, Gym 3 times a week would translate to 3/7.
 # 0 is for tracking a habit. 0 frequency habits will not warn or score.
+# ! before a frequency is for a habit to avoid. !2/7 allows it at most
+# twice a week, and ! on its own not at all.
 # Examples:
 
 Gymmed: 3/7
@@ -52,18 +147,34 @@ Cleaned House: 7
 Called Mom: 1w
 Tracked Finances: 15
 New Skill: 90
-Too much coffee: 0
+Too much coffee: !
 Used harsh: 0
 `
 
-// ParseHabitFrequency parses the frequency string and sets Target and Interval
-func (habit *Habit) ParseHabitFrequency() {
+// ParseHabitFrequency parses the frequency string and sets Target and Interval.
+// A target with a unit, e.g. "8 glasses/1" or "20km/7", makes an amount habit
+// with a Target of 1 and the Amount to record within the interval. A "!"
+// prefix, e.g. "!2/7", makes an avoidance habit, see setAvoidFrequency,
+// weekday names, e.g. "mon,wed,fri", a habit due on those days of the week,
+// and a calendar period after the slash, e.g. "2/month", a target counted
+// in each calendar week, month or quarter rather than a rolling interval.
+func (habit *Habit) ParseHabitFrequency() error {
+	if limit, ok := strings.CutPrefix(habit.Frequency, "!"); ok {
+		return habit.setAvoidFrequency(strings.TrimSpace(limit))
+	}
+	if weekdays, ok := parseWeekdays(habit.Frequency); ok {
+		habit.Target = 1
+		habit.Interval = 1
+		habit.Weekdays = weekdays
+		return nil
+	}
 	freq := strings.Split(habit.Frequency, "/")
+	if amount, unit, ok := parseAmount(strings.TrimSpace(freq[0])); ok && len(freq) == 2 {
+		return habit.setAmountFrequency(amount, unit, freq[1])
+	}
 	target, err := parseDay(strings.TrimSpace(freq[0]))
 	if err != nil {
-		fmt.Println("Error: A frequency in your habit file has a non-integer before the slash.")
-		fmt.Println("The problem entry to fix is: " + habit.Name + " : " + habit.Frequency)
-		os.Exit(1)
+		return fmt.Errorf("frequency has a non-integer before the slash")
 	}
 
 	var interval int
@@ -74,21 +185,140 @@ func (habit *Habit) ParseHabitFrequency() {
 			interval = target
 			target = 1
 		}
+	} else if days, ok := calendarPeriods[strings.ToLower(strings.TrimSpace(freq[1]))]; ok {
+		habit.Period = strings.ToLower(strings.TrimSpace(freq[1]))
+		interval = days
 	} else {
 		interval, err = parseDay(strings.TrimSpace(freq[1]))
 		if err != nil || interval == 0 {
-			fmt.Println("Error: A frequency in your habit file has a non-integer or zero after the slash.")
-			fmt.Println("The problem entry to fix is: " + habit.Name + " : " + habit.Frequency)
-			os.Exit(1)
+			return fmt.Errorf("frequency has a non-integer or zero after the slash")
 		}
 	}
 	if target > interval {
-		fmt.Println("Error: A frequency in your habit file has a target value greater than the interval period.")
-		fmt.Println("The problem entry to fix is: " + habit.Name + " : " + habit.Frequency)
-		os.Exit(1)
+		return fmt.Errorf("frequency has a target value greater than the interval period")
 	}
 	habit.Target = target
 	habit.Interval = interval
+	return nil
+}
+
+// parseHabitDates sets the habit's start and end dates from the third field
+// of its habit line: an end date "YYYY-MM-DD", a start date "YYYY-MM-DD.." or
+// both, "YYYY-MM-DD..YYYY-MM-DD"
+func (h *Habit) parseHabitDates(field string) error {
+	if field == "" {
+		return nil
+	}
+	startStr, endStr, isRange := strings.Cut(field, "..")
+	if !isRange {
+		startStr, endStr = "", field
+	}
+	if startStr = strings.TrimSpace(startStr); startStr != "" {
+		start, err := civil.ParseDate(startStr)
+		if err != nil {
+			return fmt.Errorf("invalid start date (expected format: YYYY-MM-DD..YYYY-MM-DD)")
+		}
+		h.StartRecord = start
+	}
+	if endStr = strings.TrimSpace(endStr); endStr != "" {
+		end, err := civil.ParseDate(endStr)
+		if err != nil {
+			return fmt.Errorf("invalid end date (expected format: YYYY-MM-DD)")
+		}
+		h.EndRecord = end
+	}
+	if !h.StartRecord.IsZero() && !h.EndRecord.IsZero() && h.EndRecord.Before(h.StartRecord) {
+		return fmt.Errorf("end date is before the start date")
+	}
+	return nil
+}
+
+// setAvoidFrequency sets an avoidance habit's Limit and Interval from the
+// frequency after its "!": "2/7" allows it twice per 7 days, "7" once
+// per 7 days and "0", or nothing at all, never. Its Target is 1, as it has a
+// streak to keep like any other habit.
+func (habit *Habit) setAvoidFrequency(limit string) error {
+	if limit == "" {
+		limit = "0"
+	}
+	quota := Habit{Frequency: limit}
+	if err := quota.ParseHabitFrequency(); err != nil {
+		return err
+	}
+	if quota.Avoid || quota.Amount > 0 || quota.Period != "" {
+		return fmt.Errorf("frequency of a habit to avoid takes a count, e.g. !2/7 for at most twice per 7 days")
+	}
+	habit.Avoid = true
+	habit.Limit = quota.Target
+	habit.Target = 1
+	habit.Interval = quota.Interval
+	return nil
+}
+
+// setAmountFrequency sets an amount habit's Target, Interval and Amount, and
+// its Unit where the habit has none yet
+func (habit *Habit) setAmountFrequency(amount float64, unit string, intervalField string) error {
+	if amount <= 0 {
+		return fmt.Errorf("frequency has a zero amount")
+	}
+	interval, err := parseDay(strings.TrimSpace(intervalField))
+	if err != nil || interval == 0 {
+		return fmt.Errorf("frequency has a non-integer or zero after the slash")
+	}
+	habit.Target = 1
+	habit.Interval = interval
+	habit.Amount = amount
+	if habit.Unit == "" {
+		habit.Unit = unit
+	}
+	return nil
+}
+
+// calendarPeriods are the calendar periods a target can be counted in, with
+// the days their Interval is taken as where a length is needed
+var calendarPeriods = map[string]int{"week": 7, "month": 30, "quarter": 91}
+
+// weekdayNames are the days of the week weekday schedules are written with
+var weekdayNames = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// parseWeekdays parses a weekday schedule, comma separated days of the week
+// such as "mon,wed,fri" or "Tuesday", named by at least their first three
+// letters. It returns false for frequencies that are not one.
+func parseWeekdays(input string) ([]time.Weekday, bool) {
+	var weekdays []time.Weekday
+	for _, field := range strings.Split(input, ",") {
+		name := strings.ToLower(strings.TrimSpace(field))
+		if len(name) < 3 {
+			return nil, false
+		}
+		day := slices.IndexFunc(weekdayNames, func(full string) bool { return strings.HasPrefix(full, name) })
+		if day == -1 {
+			return nil, false
+		}
+		if !slices.Contains(weekdays, time.Weekday(day)) {
+			weekdays = append(weekdays, time.Weekday(day))
+		}
+	}
+	return weekdays, true
+}
+
+// parseAmount splits an amount target such as "8 glasses" or "2.5km" into its
+// amount and unit. Targets without a unit, or with the "w" weeks suffix, are
+// not amounts.
+func parseAmount(input string) (float64, string, bool) {
+	index := strings.IndexFunc(input, func(r rune) bool { return isNotDigit(r) && r != '.' })
+	if index <= 0 {
+		return 0, "", false
+	}
+	unit := strings.TrimSpace(input[index:])
+	if unit == "w" {
+		return 0, "", false
+	}
+	amount, err := strconv.ParseFloat(input[:index], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return amount, unit, true
 }
 
 func parseDay(input string) (int, error) {
@@ -119,8 +349,12 @@ func isNotDigit(r rune) bool {
 	return r > '9' || r < '0'
 }
 
-// LoadHabitsConfig loads habits in config file ordered slice
-func LoadHabitsConfig(configDir string) ([]*Habit, int) {
+// LoadHabitsConfig loads habits in config file ordered slice, with the habits
+// of files named by "include <path>" lines spliced in where they appear.
+// Malformed lines and duplicate habits are skipped and returned as warnings;
+// an invalid frequency or dates, a missing included file or an include
+// cycle is returned as a *ParseError since it cannot be safely skipped.
+func LoadHabitsConfig(configDir string) ([]*Habit, int, []*ParseError, error) {
 	habitsPath := filepath.Join(configDir, "habits")
 	file, err := os.Open(habitsPath)
 	if err != nil {
@@ -128,55 +362,125 @@ func LoadHabitsConfig(configDir string) ([]*Habit, int) {
 			// Check for common cloud storage scenarios
 			icloudPath := filepath.Join(configDir, ".habits.icloud")
 			if _, err := os.Stat(icloudPath); err == nil {
-				fmt.Println("Error: Your habits file is currently syncing with iCloud.")
-				fmt.Println("The file appears as '.habits.icloud' while syncing.")
-				fmt.Println("Please wait for sync to complete, or disable iCloud for the harsh folder.")
-				os.Exit(1)
+				return nil, 0, nil, fmt.Errorf("habits file is currently syncing with iCloud (it appears as '.habits.icloud'); wait for sync to complete, or disable iCloud for the harsh folder")
 			}
-			
+
 			// Check if config directory exists but habits file doesn't
-			if _, err := os.Stat(configDir); err == nil {
-				fmt.Printf("Error: Habits file not found at %s\n", habitsPath)
-				fmt.Println("This might be your first time using harsh.")
-				fmt.Println("Run 'harsh' without arguments to create an example habits file.")
-				os.Exit(1)
+			if _, statErr := os.Stat(configDir); statErr == nil {
+				return nil, 0, nil, fmt.Errorf("habits file not found at %s; run 'harsh' without arguments to create an example habits file: %w", habitsPath, err)
 			}
-			
+
 			// Config directory doesn't exist
-			fmt.Printf("Error: Configuration directory not found at %s\n", configDir)
-			fmt.Println("Run 'harsh' without arguments to initialize your configuration.")
-			os.Exit(1)
+			return nil, 0, nil, fmt.Errorf("configuration directory not found at %s; run 'harsh' without arguments to initialize your configuration: %w", configDir, err)
 		}
-		
+
 		// For permission errors or other issues, provide context
 		if os.IsPermission(err) {
-			fmt.Printf("Error: Permission denied accessing habits file at %s\n", habitsPath)
-			fmt.Println("Check file permissions or try running with appropriate privileges.")
-			os.Exit(1)
+			return nil, 0, nil, fmt.Errorf("permission denied accessing habits file at %s (check file permissions): %w", habitsPath, err)
 		}
-		
-		// For other errors, use the original behavior but with more context
-		fmt.Printf("Error opening habits file at %s: %v\n", habitsPath, err)
-		os.Exit(1)
+
+		return nil, 0, nil, fmt.Errorf("cannot open habits file at %s: %w", habitsPath, err)
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	loader := newHabitsLoader(configDir)
+	if err := loader.load(file, habitsPath, ""); err != nil {
+		return nil, 0, loader.warnings, err
+	}
+	habits := loader.habits
+	warnings := loader.warnings
+	for _, habit := range habits {
+		habit.GroupPauses = slices.Clone(loader.pauses[""])
+		if habit.Heading != "" {
+			habit.GroupPauses = append(habit.GroupPauses, loader.pauses[habit.Heading]...)
+		}
+	}
+
+	maxHabitNameLength := 0
+	for _, habit := range habits {
+		if len(habit.Name) > maxHabitNameLength {
+			maxHabitNameLength = len(habit.Name)
+		}
+	}
+
+	return habits, maxHabitNameLength + 10, warnings, nil
+}
+
+// habitsLoader collects the habits of a habits file and the files it includes
+type habitsLoader struct {
+	configDir string
+	habits    []*Habit
+	warnings  []*ParseError
+	defined   map[string]habitSource // Where each habit name is defined
+	including []string               // Files being read, outermost first
+	pauses    map[string][]Pause     // Pauses of pause lines by heading, "" for all habits
+}
+
+// habitSource is the file and line a habit is defined on
+type habitSource struct {
+	file string
+	line int
+}
+
+// newHabitsLoader returns a loader for the habits file in configDir
+func newHabitsLoader(configDir string) *habitsLoader {
+	return &habitsLoader{configDir: configDir, defined: map[string]habitSource{}, pauses: map[string][]Pause{}}
+}
+
+// loadHabitSources reads the habits file in configDir and the files it
+// includes, for changes that need the file and line each habit is defined on
+func loadHabitSources(configDir string) (*habitsLoader, error) {
+	habitsPath := filepath.Join(configDir, "habits")
+	habitsFile, err := os.Open(habitsPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read habits file %s: %w", habitsPath, err)
+	}
+	defer habitsFile.Close()
+	loader := newHabitsLoader(configDir)
+	if err := loader.load(habitsFile, habitsPath, ""); err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
 
-	var heading string
-	var habits []*Habit
+// load reads the habits file habitsPath from r, starting under heading.
+// Malformed lines are skipped and kept as warnings, as are habits defined
+// again after their first definition.
+func (l *habitsLoader) load(r io.Reader, habitsPath string, heading string) error {
+	l.including = append(l.including, habitsPath)
+	defer func() { l.including = l.including[:len(l.including)-1] }()
+
+	scanner := bufio.NewScanner(r)
 	lineCount := 0
-	
+
+	warn := func(text string, reason string) {
+		l.warnings = append(l.warnings, &ParseError{File: habitsPath, Line: lineCount, Text: text, Reason: reason})
+	}
+
 	for scanner.Scan() {
 		lineCount++
 		line := scanner.Text()
-		
+
 		if len(line) > 0 {
-			if line[0] == '!' {
+			if pattern, ok := strings.CutPrefix(line, "include "); ok && !strings.Contains(line, ": ") {
+				// Included habits start under the current heading, which
+				// their own headings do not change for the lines after
+				if err := l.include(strings.TrimSpace(pattern), habitsPath, lineCount, line, heading); err != nil {
+					return err
+				}
+			} else if pause, ok := strings.CutPrefix(line, "pause "); ok && !strings.Contains(line, ": ") {
+				// Pauses before any heading are for all habits, and under
+				// a heading for the habits under it
+				p, err := ParsePause(pause)
+				if err != nil {
+					warn(line, "skipping "+err.Error())
+					continue
+				}
+				l.pauses[heading] = append(l.pauses[heading], p)
+			} else if line[0] == '!' {
 				// Parse heading line
 				if !strings.Contains(line, "! ") {
-					fmt.Printf("Warning: Malformed heading at line %d: %s\n", lineCount, line)
-					fmt.Println("Expected format: ! Heading Name")
+					warn(line, "malformed heading (expected format: ! Heading Name)")
 					continue
 				}
 				result := strings.Split(line, "! ")
@@ -185,89 +489,152 @@ func LoadHabitsConfig(configDir string) ([]*Habit, int) {
 				}
 			} else if line[0] != '#' {
 				// Parse habit line
-				// Format: "Habit Name: frequency" or "Habit Name: frequency: end_date"
-				if !strings.Contains(line, ": ") {
-					fmt.Printf("Warning: Skipping malformed habit at line %d: %s\n", lineCount, line)
-					fmt.Println("Expected format: Habit Name: frequency [: YYYY-MM-DD]")
+				// Format: "Habit Name: frequency" or "Habit Name: frequency: end_date",
+				// the name optionally followed by former names "(was: Old Name)"
+				// and the line by attributes "[unit=km; tags=health]"
+				text, attrs, err := splitHabitAttributes(line)
+				if err != nil {
+					warn(line, err.Error())
+				}
+				fields, aliases := splitHabitAliases(text)
+				if !strings.Contains(fields, ": ") {
+					warn(line, "skipping malformed habit (expected format: Habit Name: frequency [: YYYY-MM-DD])")
 					continue
 				}
 
-				result := strings.Split(line, ": ")
+				result := splitFields(fields, ": ")
 				if len(result) < 2 {
-					fmt.Printf("Warning: Skipping habit with missing frequency at line %d: %s\n", lineCount, line)
+					warn(line, "skipping habit with missing frequency")
 					continue
 				}
 
-				habitName := strings.TrimSpace(result[0])
+				habitName := UnescapeField(strings.TrimSpace(result[0]))
 				frequency := strings.TrimSpace(result[1])
 
 				if habitName == "" {
-					fmt.Printf("Warning: Skipping habit with empty name at line %d\n", lineCount)
+					warn(line, "skipping habit with empty name")
 					continue
 				}
 
 				if frequency == "" {
-					fmt.Printf("Warning: Skipping habit '%s' with empty frequency at line %d\n", habitName, lineCount)
+					warn(line, "skipping habit with empty frequency")
 					continue
 				}
 
-				h := Habit{Heading: heading, Name: habitName, Frequency: frequency}
+				h := Habit{Heading: heading, Name: habitName, Frequency: frequency, Aliases: aliases}
 
-				// Parse optional end date (third field)
+				// Parse optional start and end dates (third field)
 				if len(result) >= 3 {
-					dateStr := strings.TrimSpace(result[2])
-					if dateStr != "" {
-						endDate, err := civil.ParseDate(dateStr)
-						if err != nil {
-							fmt.Printf("Error: Invalid end date '%s' for habit '%s' at line %d\n", dateStr, habitName, lineCount)
-							fmt.Println("Expected format: YYYY-MM-DD (e.g., 2024-06-15)")
-							os.Exit(1)
-						}
-						h.EndRecord = endDate
+					if err := (&h).parseHabitDates(strings.TrimSpace(result[2])); err != nil {
+						return &ParseError{File: habitsPath, Line: lineCount, Text: line, Reason: err.Error()}
 					}
 				}
 
-				// ParseHabitFrequency may call os.Exit on invalid frequency
-				// This is the intended behavior for invalid config
-				(&h).ParseHabitFrequency()
-				habits = append(habits, &h)
+				if err := (&h).ParseHabitFrequency(); err != nil {
+					return &ParseError{File: habitsPath, Line: lineCount, Text: line, Reason: err.Error()}
+				}
+				if err := h.applyAttributes(attrs); err != nil {
+					warn(line, err.Error())
+				}
+				if first, ok := l.defined[habitName]; ok {
+					warn(line, fmt.Sprintf("skipping duplicate habit, already defined at %s:%d", first.file, first.line))
+					continue
+				}
+				l.defined[habitName] = habitSource{file: habitsPath, line: lineCount}
+				l.habits = append(l.habits, &h)
 			}
 		}
 	}
 
-	maxHabitNameLength := 0
-	for _, habit := range habits {
-		if len(habit.Name) > maxHabitNameLength {
-			maxHabitNameLength = len(habit.Name)
-		}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed reading habits file %s: %w", habitsPath, err)
 	}
+	return nil
 
-	return habits, maxHabitNameLength + 10
 }
 
-// FindConfigFiles checks os relevant habits and log file exist, returns path
-// If they do not exist, calls CreateExampleHabitsFile and CreateNewLogFile
-func FindConfigFiles() string {
-	configDir := os.Getenv("HARSHPATH")
+// include reads the habits files matching pattern, found on line lineCount
+// of habitsPath, in place. Relative patterns are relative to the config dir
+// and "~/" is the home directory. A pattern without glob characters must
+// name an existing file, and a file cannot include itself, even indirectly.
+func (l *habitsLoader) include(pattern string, habitsPath string, lineCount int, line string, heading string) error {
+	fail := func(reason string) error {
+		return &ParseError{File: habitsPath, Line: lineCount, Text: line, Reason: reason}
+	}
+	if pattern == "" {
+		return fail("include has no file (expected format: include path/to/habits)")
+	}
+	if rest, ok := strings.CutPrefix(pattern, "~/"); ok {
+		pattern = filepath.Join(os.Getenv("HOME"), rest)
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(l.configDir, pattern)
+	}
 
-	if len(configDir) == 0 {
-		if runtime.GOOS == "windows" {
-			configDir = filepath.Join(os.Getenv("APPDATA"), "harsh")
-		} else {
-			configDir = filepath.Join(os.Getenv("HOME"), ".config/harsh")
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fail("invalid include pattern")
+	}
+	if len(matches) == 0 {
+		if !strings.ContainsAny(pattern, "*?[") {
+			return fail("included file not found")
 		}
+		l.warnings = append(l.warnings, &ParseError{File: habitsPath, Line: lineCount, Text: line, Reason: "include matches no files"})
+		return nil
 	}
 
-	if _, err := os.Stat(filepath.Join(configDir, "habits")); err == nil {
-	} else {
-		welcome(configDir)
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			continue
+		}
+		for i, including := range l.including {
+			if sameFile(including, match) {
+				cycle := append(slices.Clone(l.including[i:]), match)
+				return fail("include cycle: " + strings.Join(cycle, " -> "))
+			}
+		}
+		file, err := os.Open(match)
+		if err != nil {
+			return fail(fmt.Sprintf("cannot open included file: %v", err))
+		}
+		err = l.load(file, match, heading)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a string, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+// FindConfigFiles checks the habits and log files of paths exist.
+// If they do not exist, calls CreateExampleHabitsFile and CreateNewLogFile
+// and reports created as true so the caller can welcome the new user.
+func FindConfigFiles(paths Paths) (created bool, err error) {
+	if _, err := os.Stat(filepath.Join(paths.ConfigDir, "habits")); err == nil {
+		return false, nil
 	}
 
-	return configDir
+	if err := CreateExampleHabitsFile(paths.ConfigDir); err != nil {
+		return false, err
+	}
+	if err := CreateNewLogFile(paths.LogDir); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // CreateExampleHabitsFile writes a fresh Habits file for people to follow
-func CreateExampleHabitsFile(configDir string) {
+func CreateExampleHabitsFile(configDir string) error {
 	fileName := filepath.Join(configDir, "habits")
 	_, err := os.Stat(fileName)
 	if os.IsNotExist(err) {
@@ -276,46 +643,29 @@ func CreateExampleHabitsFile(configDir string) {
 		}
 		f, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 		if err != nil {
-			log.Fatalf("error opening file: %v", err)
+			return fmt.Errorf("error creating habits file %s: %w", fileName, err)
 		}
 		defer f.Close()
-		f.WriteString(DEFAULT_HABITS)
+		if _, err := f.WriteString(DEFAULT_HABITS); err != nil {
+			return fmt.Errorf("error writing habits file %s: %w", fileName, err)
+		}
 	}
+	return nil
 }
 
 // CreateNewLogFile writes an empty log file for people to start tracking into
-func CreateNewLogFile(configDir string) {
-	fileName := filepath.Join(configDir, "log")
+func CreateNewLogFile(logDir string) error {
+	fileName := filepath.Join(logDir, "log")
 	_, err := os.Stat(fileName)
 	if os.IsNotExist(err) {
-		if _, err := os.Stat(configDir); os.IsNotExist(err) {
-			os.MkdirAll(configDir, os.ModePerm)
+		if _, err := os.Stat(logDir); os.IsNotExist(err) {
+			os.MkdirAll(logDir, os.ModePerm)
 		}
-		_, err := os.OpenFile(fileName, os.O_RDONLY|os.O_CREATE, 0644)
+		f, err := os.OpenFile(fileName, os.O_RDONLY|os.O_CREATE, 0644)
 		if err != nil {
-			log.Fatalf("error opening file: %v", err)
+			return fmt.Errorf("error creating log file %s: %w", fileName, err)
 		}
+		f.Close()
 	}
-}
-
-// welcome a new user and creates example habits and log files
-func welcome(configDir string) {
-	CreateExampleHabitsFile(configDir)
-	CreateNewLogFile(configDir)
-	fmt.Println("Welcome to harsh!")
-	fmt.Println("Created " + filepath.Join(configDir, "habits") + "   This file lists your habits.")
-	fmt.Println("Created " + filepath.Join(configDir, "log") + "      This file is your habit log.")
-	fmt.Println("")
-	fmt.Println("No habits of your own yet?")
-	fmt.Println("Open your habits file @ " + filepath.Join(configDir, "habits"))
-	fmt.Println("with a text editor (nano, vim, VS Code, Atom, emacs) and modify and save the habits list.")
-	fmt.Println("Then:")
-	fmt.Println("Run       harsh ask     to start tracking")
-	fmt.Println("Running   harsh todo    will show you undone habits for today.")
-	fmt.Println("Running   harsh log     will show you a consistency graph of your efforts.")
-	fmt.Println("                        (the graph gets way cooler looking over time.")
-	fmt.Println("For more depth, you can read https://github.com/wakatara/harsh#usage")
-	fmt.Println("")
-	fmt.Println("Happy tracking! I genuinely hope this helps you with your goals. Buena suerte!")
-	os.Exit(0)
+	return nil
 }
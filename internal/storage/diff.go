@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed or '+' added
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the changes from one file content to another as a
+// unified diff, as diff -u and git show them, or an empty string if they
+// are the same
+func unifiedDiff(fromName string, toName string, from string, to string) string {
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	var out strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change, and the changes close enough to share its hunk
+		first := slices.IndexFunc(ops[start:], func(op diffOp) bool { return op.kind != ' ' })
+		if first == -1 {
+			break
+		}
+		first += start
+		last := first
+		for i := first + 1; i < len(ops) && i-last <= 2*diffContext+1; i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			}
+		}
+		hunkStart := max(start, first-diffContext)
+		hunkEnd := min(len(ops), last+diffContext+1)
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		fromLine, toLine := 1, 1
+		for _, op := range ops[:hunkStart] {
+			if op.kind != '+' {
+				fromLine++
+			}
+			if op.kind != '-' {
+				toLine++
+			}
+		}
+		fromLen, toLen := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				fromLen++
+			}
+			if op.kind != '-' {
+				toLen++
+			}
+		}
+		// An empty side is numbered by the line before it
+		if fromLen == 0 {
+			fromLine--
+		}
+		if toLen == 0 {
+			toLine--
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", fromLine, fromLen, toLine, toLen)
+		for _, op := range ops[hunkStart:hunkEnd] {
+			out.WriteString(string(op.kind) + op.line + "\n")
+		}
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+// splitLines splits file content into its lines, without their line endings
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script turning a into b, using Myers'
+// algorithm
+func diffLines(a []string, b []string) []diffOp {
+	n, m := len(a), len(b)
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace holds, for each number of edits d, the furthest reaching paths
+	// found with d-1 edits, on the diagonals d+1 either side of the middle
+	var trace [][]int
+search:
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end to recover the edits, last first
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		w := trace[d]
+		at := func(k int) int { return w[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', b[y-1]})
+			} else {
+				ops = append(ops, diffOp{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(ops)
+	return ops
+}
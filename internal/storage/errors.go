@@ -0,0 +1,23 @@
+package storage
+
+import "fmt"
+
+// ParseError describes a problem found on a single line of the habits or log file
+type ParseError struct {
+	File   string // Path of the file being parsed
+	Line   int    // 1-based line number, 0 if not tied to a line
+	Text   string // The offending text
+	Reason string // Why the text could not be used
+}
+
+// Error formats the parse error as file:line: reason: text
+func (e *ParseError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+	if e.Text == "" {
+		return fmt.Sprintf("%s: %s", location, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, e.Reason, e.Text)
+}
@@ -0,0 +1,95 @@
+package storage
+
+import "strings"
+
+// Fields of the habits and log files are separated by colons, so text written
+// to them is escaped with a backslash where it could be mistaken for a
+// separator: a colon next to a space or at either end of the field becomes
+// "\:", a newline "\n", and a backslash that would otherwise start one of
+// these escapes "\\". Colons in times like "06:30" and URLs are left as is,
+// and any other backslash is literal. Files written before escaping existed
+// read back unchanged unless a field holds one of the escapes, which is then
+// unescaped: an old comment "C:\new" reads as "C:", a newline and "ew".
+
+// EscapeField escapes text for a field of the habits or log file
+func EscapeField(s string) string {
+	if !strings.ContainsAny(s, ":\\\n") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\\':
+			if i == len(s)-1 || isEscapable(s[i+1]) {
+				b.WriteString(`\\`)
+			} else {
+				b.WriteByte(c)
+			}
+		case ':':
+			if i == 0 || i == len(s)-1 || s[i-1] == ' ' || s[i+1] == ' ' {
+				b.WriteString(`\:`)
+			} else {
+				b.WriteByte(c)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// UnescapeField reverses EscapeField
+func UnescapeField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isEscapable(s[i+1]) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// isEscapable reports whether c follows a backslash in an escape sequence
+func isEscapable(c byte) bool {
+	return c == '\\' || c == ':' || c == 'n'
+}
+
+// splitFields splits a habits or log file line on sep, skipping separators
+// whose colon is escaped. Fields are returned still escaped.
+func splitFields(line string, sep string) []string {
+	if !strings.Contains(line, `\`) {
+		return strings.Split(line, sep)
+	}
+
+	var fields []string
+	start := 0
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) && isEscapable(line[i+1]) {
+			i++
+			continue
+		}
+		if strings.HasPrefix(line[i:], sep) {
+			fields = append(fields, line[start:i])
+			i += len(sep) - 1
+			start = i + 1
+		}
+	}
+	return append(fields, line[start:])
+}
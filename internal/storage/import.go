@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/civil"
+)
+
+// ImportedHabit is a habit and its history read from another habit tracker
+type ImportedHabit struct {
+	Habit   Habit                  // Name, Frequency, and optional Heading and EndRecord
+	Entries map[civil.Date]Outcome // Outcome recorded for each day
+}
+
+// ImportOptions controls how imported habits map onto the habits file
+type ImportOptions struct {
+	Names map[string]string // Imported name to the harsh name it is logged under
+	Skip  []string          // Imported names left out of the import
+}
+
+// ImportPlan lists what importing habits and their history would change
+type ImportPlan struct {
+	NewHabits []*Habit               // Habits to add to the habits file
+	Matched   map[string]string      // Imported name to the existing habit it joins
+	Additions map[DailyHabit]Outcome // Entries missing from the log
+	Existing  int                    // Imported days the log already has, left as they are
+}
+
+// PlanImport works out which imported habits are new and which entries the
+// log is missing. An imported habit whose name, after opts.Names, matches a
+// habit or former name in habits (ignoring case) is logged under that habit;
+// the rest are added to the habits file. Days already logged for a habit are
+// never overwritten.
+func PlanImport(imported []*ImportedHabit, opts ImportOptions, habits []*Habit, entries Entries) (*ImportPlan, error) {
+	known := map[string]bool{}
+	for _, ih := range imported {
+		known[ih.Habit.Name] = true
+	}
+	for from := range opts.Names {
+		if !known[from] {
+			return nil, fmt.Errorf("no imported habit named %q to map", from)
+		}
+	}
+	for _, name := range opts.Skip {
+		if !known[name] {
+			return nil, fmt.Errorf("no imported habit named %q to skip", name)
+		}
+	}
+
+	plan := &ImportPlan{
+		Matched:   map[string]string{},
+		Additions: map[DailyHabit]Outcome{},
+	}
+	added := map[string]*Habit{}
+	for _, ih := range imported {
+		if slices.Contains(opts.Skip, ih.Habit.Name) {
+			continue
+		}
+		name := ih.Habit.Name
+		if mapped, ok := opts.Names[name]; ok {
+			name = strings.TrimSpace(mapped)
+		}
+
+		if existing := matchHabit(habits, name); existing != nil {
+			plan.Matched[ih.Habit.Name] = existing.Name
+			name = existing.Name
+		} else if h, ok := added[strings.ToLower(name)]; ok {
+			// Several imported habits mapped onto one new habit
+			name = h.Name
+		} else {
+			if err := validateHabitName(name); err != nil {
+				return nil, err
+			}
+			h := ih.Habit
+			h.Name = name
+			if err := h.ParseHabitFrequency(); err != nil {
+				return nil, fmt.Errorf("imported habit %q: %w", ih.Habit.Name, err)
+			}
+			added[strings.ToLower(name)] = &h
+			plan.NewHabits = append(plan.NewHabits, &h)
+		}
+
+		for day, outcome := range ih.Entries {
+			key := DailyHabit{Day: day, Habit: name}
+			if _, ok := entries[key]; ok {
+				plan.Existing++
+				continue
+			}
+			if _, ok := plan.Additions[key]; ok {
+				continue
+			}
+			plan.Additions[key] = outcome
+		}
+	}
+	return plan, nil
+}
+
+// matchHabit returns the habit named name, or formerly named name, ignoring case
+func matchHabit(habits []*Habit, name string) *Habit {
+	for _, habit := range habits {
+		for _, n := range habit.Names() {
+			if strings.EqualFold(n, name) {
+				return habit
+			}
+		}
+	}
+	return nil
+}
+
+// validateHabitName reports names the habits file cannot hold
+func validateHabitName(name string) error {
+	if name == "" {
+		return fmt.Errorf("no habit name given")
+	}
+	if strings.Contains(name, "(was:") || name[0] == '!' || name[0] == '#' {
+		return fmt.Errorf("invalid habit name %q (names cannot contain \"(was:\", or start with ! or #)", name)
+	}
+	return nil
+}
+
+// Apply adds the new habits to the habits file, under heading if they have
+// none of their own, then writes the missing entries through the repository.
+// Returns the number of entries written.
+func (p *ImportPlan) Apply(repository Repository, heading string) (int, error) {
+	if err := AppendHabits(repository.GetConfigDir(), heading, p.NewHabits); err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, key := range SortedKeys(p.Additions) {
+		o := p.Additions[key]
+		if err := repository.WriteEntry(key.Day, key.Habit, o.Result, o.Comment, o.AmountString(), o.Time); err != nil {
+			return added, err
+		}
+		added++
+	}
+	return added, nil
+}
+
+// AppendHabits adds habits to the end of the habits file, each group under
+// a "! Heading" line for its heading, or for heading if it has none
+func AppendHabits(configDir string, heading string, habits []*Habit) error {
+	if len(habits) == 0 {
+		return nil
+	}
+
+	unlock, err := LockConfigDir(configDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	habitsPath := filepath.Join(configDir, "habits")
+	content, err := os.ReadFile(habitsPath)
+	if err != nil {
+		return fmt.Errorf("cannot read habits file %s: %w", habitsPath, err)
+	}
+
+	var b strings.Builder
+	b.Write(content)
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		b.WriteString("\n")
+	}
+	current := ""
+	for _, h := range habits {
+		if h.Heading == "" {
+			h.Heading = heading
+		}
+		if h.Heading != current && h.Heading != "" {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("! " + h.Heading + "\n")
+		}
+		current = h.Heading
+		b.WriteString(FormatHabit(h) + "\n")
+	}
+	return replaceFile(habitsPath, []byte(b.String()))
+}
+
+// FormatHabit renders a habit as a line of the habits file
+func FormatHabit(h *Habit) string {
+	line := EscapeField(h.Name)
+	if len(h.Aliases) > 0 {
+		escaped := make([]string, len(h.Aliases))
+		for i, alias := range h.Aliases {
+			escaped[i] = EscapeField(alias)
+		}
+		line += " (was: " + strings.Join(escaped, ", ") + ")"
+	}
+	line += ": " + h.Frequency
+	switch {
+	case !h.StartRecord.IsZero() && !h.EndRecord.IsZero():
+		line += ": " + h.StartRecord.String() + ".." + h.EndRecord.String()
+	case !h.StartRecord.IsZero():
+		line += ": " + h.StartRecord.String() + ".."
+	case !h.EndRecord.IsZero():
+		line += ": " + h.EndRecord.String()
+	}
+	if attrs := formatHabitAttributes(h); attrs != "" {
+		line += " " + attrs
+	}
+	return line
+}
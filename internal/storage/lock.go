@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned when another harsh process holds the config dir lock
+var ErrLocked = errors.New("another harsh process is writing to your habit files")
+
+// lockFileName is created in the config dir and held while harsh writes
+const lockFileName = ".harsh.lock"
+
+// lockWait is how long to wait for another harsh process to finish writing
+const lockWait = time.Second
+
+// LockConfigDir takes the advisory lock guarding writes to the habits and
+// log files in configDir. If another harsh process holds it, LockConfigDir
+// retries for a moment before giving up with ErrLocked. Call the returned
+// unlock function once the write is complete.
+func LockConfigDir(configDir string) (unlock func() error, err error) {
+	lockPath := filepath.Join(configDir, lockFileName)
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("configuration directory does not exist: %s", configDir)
+		}
+		return nil, fmt.Errorf("cannot open lock file %s: %w", lockPath, err)
+	}
+
+	deadline := time.Now().Add(lockWait)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("cannot lock %s: %w", lockPath, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%w (lock held on %s), try again in a moment", ErrLocked, lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return func() error {
+		unlockErr := unlockFile(f)
+		if err := f.Close(); err != nil && unlockErr == nil {
+			unlockErr = err
+		}
+		return unlockErr
+	}, nil
+}
+
+// WriteFileAtomic writes content to a temporary file in the same directory
+// and renames it over fileName, so readers never see a partially written file
+func WriteFileAtomic(fileName string, content []byte, perm os.FileMode) error {
+	dir, base := filepath.Split(fileName)
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("permission denied writing to %s (check directory permissions)", dir)
+		}
+		return fmt.Errorf("cannot create temporary file for %s: %w", fileName, err)
+	}
+	tmpName := tmp.Name()
+	// Best effort cleanup; after a successful rename the temp file is gone
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", fileName, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", fileName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file for %s: %w", fileName, err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", fileName, err)
+	}
+	if err := os.Rename(tmpName, fileName); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", fileName, err)
+	}
+	return nil
+}
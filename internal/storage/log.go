@@ -2,22 +2,49 @@ package storage
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/civil"
 )
 
 // Outcome is the explicit recorded result of a habit
-// on a day (y, n, or s) and an optional amount and comment
+// on a day (y, n, or s) and an optional amount, comment and time of day
 type Outcome struct {
 	Result  string
 	Amount  float64
 	Comment string
+	Time    string // Time of day as HH:MM, empty if not recorded
+}
+
+// TimeLayout is the layout of the optional time of day field in the log
+const TimeLayout = "15:04"
+
+// ParseTimeOfDay validates an HH:MM time of day, returning it zero padded
+func ParseTimeOfDay(s string) (string, error) {
+	t, err := time.Parse(TimeLayout, strings.TrimSpace(s))
+	if err != nil {
+		// Accept times written without a leading zero, e.g. 7:10
+		if t, err = time.Parse("3:04", strings.TrimSpace(s)); err != nil {
+			return "", fmt.Errorf("invalid time %q (expected HH:MM)", s)
+		}
+	}
+	return t.Format(TimeLayout), nil
+}
+
+// AmountString formats the outcome amount as written in the log, empty for none
+func (o Outcome) AmountString() string {
+	if o.Amount == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(o.Amount, 'f', -1, 64)
 }
 
 // DailyHabit combines Day and Habit with an Outcome to yield Entries
@@ -29,123 +56,164 @@ type DailyHabit struct {
 // Entries maps DailyHabit{ISO date + habit}: Outcome and log format
 type Entries map[DailyHabit]Outcome
 
-// LoadLog reads entries from log file
-func LoadLog(configDir string) *Entries {
-	logPath := filepath.Join(configDir, "log")
+// LoadLog reads entries from log file.
+// Malformed lines are skipped and returned as warnings the caller can report.
+func LoadLog(logDir string) (*Entries, []*ParseError, error) {
+	logPath := filepath.Join(logDir, "log")
 	file, err := os.Open(logPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Check for common cloud storage scenarios
-			icloudPath := filepath.Join(configDir, ".log.icloud")
+			icloudPath := filepath.Join(logDir, ".log.icloud")
 			if _, err := os.Stat(icloudPath); err == nil {
-				fmt.Println("Error: Your log file is currently syncing with iCloud.")
-				fmt.Println("The file appears as '.log.icloud' while syncing.")
-				fmt.Println("Please wait for sync to complete, or disable iCloud for the harsh folder.")
-				os.Exit(1)
+				return nil, nil, fmt.Errorf("log file is currently syncing with iCloud (it appears as '.log.icloud'); wait for sync to complete, or disable iCloud for the harsh folder")
 			}
-			
-			// Check if config directory exists but log file doesn't
-			if _, err := os.Stat(configDir); err == nil {
-				fmt.Printf("Error: Log file not found at %s\n", logPath)
-				fmt.Println("This might be your first time using harsh.")
-				fmt.Println("Run 'harsh' without arguments to initialize your configuration.")
-				os.Exit(1)
+
+			// Check if log directory exists but log file doesn't
+			if _, statErr := os.Stat(logDir); statErr == nil {
+				return nil, nil, fmt.Errorf("log file not found at %s; run 'harsh' without arguments to initialize your configuration: %w", logPath, err)
 			}
-			
-			// Config directory doesn't exist
-			fmt.Printf("Error: Configuration directory not found at %s\n", configDir)
-			fmt.Println("Run 'harsh' without arguments to initialize your configuration.")
-			os.Exit(1)
+
+			// Log directory doesn't exist
+			return nil, nil, fmt.Errorf("log directory not found at %s; run 'harsh' without arguments to initialize your configuration: %w", logDir, err)
 		}
-		
+
 		// For permission errors or other issues, provide context
 		if os.IsPermission(err) {
-			fmt.Printf("Error: Permission denied accessing log file at %s\n", logPath)
-			fmt.Println("Check file permissions or try running with appropriate privileges.")
-			os.Exit(1)
+			return nil, nil, fmt.Errorf("permission denied accessing log file at %s (check file permissions): %w", logPath, err)
 		}
-		
-		// For other errors, use the original behavior but with more context
-		fmt.Printf("Error opening log file at %s: %v\n", logPath, err)
-		os.Exit(1)
+
+		return nil, nil, fmt.Errorf("cannot open log file at %s: %w", logPath, err)
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	return readLog(file, logPath)
+}
+
+// LoadLogFile reads entries from a log file at an arbitrary path, such as a
+// sync conflicted copy of the log
+func LoadLogFile(logPath string) (*Entries, []*ParseError, error) {
+	file, err := os.Open(logPath)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, nil, fmt.Errorf("permission denied accessing log file at %s (check file permissions): %w", logPath, err)
+		}
+		return nil, nil, fmt.Errorf("cannot open log file at %s: %w", logPath, err)
+	}
+	defer file.Close()
+
+	return readLog(file, logPath)
+}
+
+// readLog parses log lines from r, reporting warnings against logPath
+func readLog(r io.Reader, logPath string) (*Entries, []*ParseError, error) {
+	scanner := bufio.NewScanner(r)
 
 	entries := Entries{}
+	var warnings []*ParseError
 	lineCount := 0
+
+	warn := func(text string, reason string) {
+		warnings = append(warnings, &ParseError{File: logPath, Line: lineCount, Text: text, Reason: reason})
+	}
+
 	for scanner.Scan() {
 		lineCount++
-		if len(scanner.Text()) > 0 {
-			if scanner.Text()[0] != '#' {
-				// Discards comments from read record read as result[3]
-				result := strings.Split(scanner.Text(), " : ")
-				
-				// Check for minimum required fields (date, habit, result)
-				if len(result) < 3 {
-					fmt.Printf("Warning: Skipping malformed log entry at line %d: %s\n", lineCount, scanner.Text())
-					fmt.Println("Expected format: YYYY-MM-DD : Habit Name : y/n/s : Comment : Amount")
-					continue
-				}
-				
-				cd, err := civil.ParseDate(result[0])
-				if err != nil {
-					fmt.Printf("Warning: Skipping log entry with invalid date at line %d: %s\n", lineCount, result[0])
-					continue
-				}
-				
-				// Validate habit name is not empty
-				if strings.TrimSpace(result[1]) == "" {
-					fmt.Printf("Warning: Skipping log entry with empty habit name at line %d\n", lineCount)
-					continue
-				}
-				
-				// Validate result is y, n, or s
-				result[2] = strings.TrimSpace(result[2])
-				if result[2] != "y" && result[2] != "n" && result[2] != "s" {
-					fmt.Printf("Warning: Skipping log entry with invalid result '%s' at line %d (expected y/n/s)\n", result[2], lineCount)
-					continue
-				}
-				
-				switch len(result) {
-				case 5:
-					if result[4] == "" {
-						result[4] = "0"
-					}
-					amount, err := strconv.ParseFloat(result[4], 64)
-					if err != nil {
-						fmt.Printf("Warning: Invalid amount '%s' at line %d, using 0.0\n", result[4], lineCount)
-						amount = 0.0
-					}
-					entries[DailyHabit{Day: cd, Habit: result[1]}] = Outcome{Result: result[2], Comment: result[3], Amount: amount}
-				case 4:
-					entries[DailyHabit{Day: cd, Habit: result[1]}] = Outcome{Result: result[2], Comment: result[3], Amount: 0.0}
-				case 3:
-					entries[DailyHabit{Day: cd, Habit: result[1]}] = Outcome{Result: result[2], Comment: "", Amount: 0.0}
-				default:
-					// This shouldn't happen due to the check above, but just in case
-					fmt.Printf("Warning: Unexpected number of fields (%d) at line %d\n", len(result), lineCount)
-				}
+		line := scanner.Text()
+		if len(line) > 0 && line[0] != '#' {
+			key, outcome, reason, ok := parseLogLine(line)
+			if reason != "" {
+				warn(line, reason)
+			}
+			if ok {
+				entries[key] = outcome
 			}
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
+		return nil, warnings, fmt.Errorf("failed reading log file %s: %w", logPath, err)
+	}
+
+	return &entries, warnings, nil
+}
+
+// parseLogLine parses a single non-comment log line. ok is false when the
+// line must be skipped; reason explains a skipped line, or a problem with
+// an entry that was still kept (such as an unreadable amount).
+func parseLogLine(line string) (key DailyHabit, outcome Outcome, reason string, ok bool) {
+	// Discards comments from read record read as result[3]
+	result := splitFields(line, " : ")
+
+	// Check for minimum required fields (date, habit, result)
+	if len(result) < 3 {
+		return key, outcome, "skipping malformed log entry (expected format: YYYY-MM-DD : Habit Name : y/n/s : Comment : Amount [: HH:MM])", false
 	}
 
-	return &entries
+	cd, err := civil.ParseDate(result[0])
+	if err != nil {
+		return key, outcome, "skipping log entry with invalid date", false
+	}
+
+	// Validate habit name is not empty
+	if strings.TrimSpace(result[1]) == "" {
+		return key, outcome, "skipping log entry with empty habit name", false
+	}
+
+	// Validate result is y, n, or s
+	result[2] = strings.TrimSpace(result[2])
+	if result[2] != "y" && result[2] != "n" && result[2] != "s" {
+		return key, outcome, "skipping log entry with invalid result (expected y/n/s)", false
+	}
+
+	key = DailyHabit{Day: cd, Habit: UnescapeField(result[1])}
+
+	// Optional time of day, the sixth field
+	var timeOfDay string
+	if len(result) == 6 {
+		if result[5] != "" {
+			if timeOfDay, err = ParseTimeOfDay(result[5]); err != nil {
+				reason = "invalid time, ignoring it"
+			}
+		}
+		result = result[:5]
+	}
+
+	switch len(result) {
+	case 5:
+		if result[4] == "" {
+			result[4] = "0"
+		}
+		amount, err := strconv.ParseFloat(result[4], 64)
+		if err != nil {
+			reason = "invalid amount, using 0.0"
+			amount = 0.0
+		}
+		return key, Outcome{Result: result[2], Comment: UnescapeField(result[3]), Amount: amount, Time: timeOfDay}, reason, true
+	case 4:
+		return key, Outcome{Result: result[2], Comment: UnescapeField(result[3]), Amount: 0.0}, "", true
+	case 3:
+		return key, Outcome{Result: result[2], Comment: "", Amount: 0.0}, "", true
+	default:
+		return key, outcome, fmt.Sprintf("skipping log entry with unexpected number of fields (%d)", len(result)), false
+	}
 }
 
-// WriteHabitLog writes the log entry for a habit to file
-func WriteHabitLog(configDir string, d civil.Date, habit string, result string, comment string, amount string) error {
-	fileName := filepath.Join(configDir, "log")
+// WriteHabitLog writes the log entry for a habit to file. timeOfDay is the
+// optional HH:MM time the habit was done, left out of the line when empty.
+func WriteHabitLog(logDir string, d civil.Date, habit string, result string, comment string, amount string, timeOfDay string) error {
+	unlock, err := LockConfigDir(logDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fileName := filepath.Join(logDir, "log")
 	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		// Provide more specific error messages based on the type of error
 		if os.IsNotExist(err) {
-			return fmt.Errorf("configuration directory does not exist: %s", configDir)
+			return fmt.Errorf("configuration directory does not exist: %s", logDir)
 		}
 		if os.IsPermission(err) {
 			return fmt.Errorf("permission denied writing to log file: %s (check file permissions)", fileName)
@@ -155,7 +223,7 @@ func WriteHabitLog(configDir string, d civil.Date, habit string, result string,
 	}
 	defer f.Close()
 
-	logEntry := d.String() + " : " + habit + " : " + result + " : " + comment + " : " + amount + "\n"
+	logEntry := formatLogEntry(d, habit, result, comment, amount, timeOfDay)
 	if _, err := f.Write([]byte(logEntry)); err != nil {
 		f.Close() // ignore error; Write error takes precedence
 		// Check for common write failure causes
@@ -171,7 +239,188 @@ func WriteHabitLog(configDir string, d civil.Date, habit string, result string,
 	return nil
 }
 
-// FirstRecords sets the FirstRecord field for habits based on their earliest entries
+// ErrEntryNotFound is returned when updating or deleting an entry the log does not contain
+var ErrEntryNotFound = errors.New("no log entry found")
+
+// formatLogEntry renders a single log line in the log file format,
+// escaping the habit name and comment so they read back unchanged.
+// The time field is only written when there is a time, so entries
+// without one keep the original five field format.
+func formatLogEntry(d civil.Date, habit string, result string, comment string, amount string, timeOfDay string) string {
+	line := d.String() + " : " + EscapeField(habit) + " : " + result + " : " + EscapeField(comment) + " : " + amount
+	if timeOfDay != "" {
+		line += " : " + timeOfDay
+	}
+	return line + "\n"
+}
+
+// logLineKey returns the DailyHabit a log line records, using the same
+// field rules as LoadLog. Comments and malformed lines report false.
+func logLineKey(line string) (DailyHabit, bool) {
+	if len(line) == 0 || line[0] == '#' {
+		return DailyHabit{}, false
+	}
+	result := splitFields(line, " : ")
+	if len(result) < 3 {
+		return DailyHabit{}, false
+	}
+	cd, err := civil.ParseDate(result[0])
+	if err != nil {
+		return DailyHabit{}, false
+	}
+	return DailyHabit{Day: cd, Habit: UnescapeField(result[1])}, true
+}
+
+// rewriteLog replaces every log line recording the day and habit of key,
+// including lines recorded under one of the habit's former names in aliases.
+// The last matching line, which is the one LoadLog keeps, is replaced in
+// place by replacement (dropped if empty) and superseded duplicates are
+// removed. All other lines, including comments, are preserved untouched.
+// An entry missing from the log is rewritten in the archive of its year it
+// was loaded from instead, and a deleted entry is removed from the archives
+// too so an archived copy does not take its place. The log dir stays locked
+// from read to replace so no write is lost.
+func rewriteLog(logDir string, key DailyHabit, aliases []string, replacement string) error {
+	unlock, err := LockConfigDir(logDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fileName := filepath.Join(logDir, "log")
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("permission denied reading log file: %s (check file permissions)", fileName)
+		}
+		return fmt.Errorf("cannot read log file %s: %w", fileName, err)
+	}
+	archives, err := FindArchives(logDir)
+	if err != nil {
+		return fmt.Errorf("cannot list log archives in %s: %w", logDir, err)
+	}
+
+	matches := func(line string) bool {
+		k, ok := logLineKey(strings.TrimRight(line, "\r\n"))
+		if !ok || k.Day != key.Day {
+			return false
+		}
+		return k.Habit == key.Habit || slices.Contains(aliases, k.Habit)
+	}
+
+	rewritten, found := rewriteLines(string(content), matches, replacement)
+	if found {
+		if err := replaceFile(fileName, []byte(rewritten)); err != nil {
+			return err
+		}
+	}
+	for _, archivePath := range archives[key.Day.Year] {
+		if found && replacement != "" {
+			break
+		}
+		archiveContent, err := readArchiveFile(archivePath)
+		if err != nil {
+			return err
+		}
+		rewritten, ok := rewriteLines(string(archiveContent), matches, replacement)
+		if !ok {
+			continue
+		}
+		found = true
+		if err := writeArchiveFile(archivePath, []byte(rewritten)); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w for %s on %s", ErrEntryNotFound, key.Habit, key.Day)
+	}
+	return nil
+}
+
+// rewriteLines replaces the last line of content that matches with
+// replacement and drops the other matching lines, reporting false if no
+// line matches
+func rewriteLines(content string, matches func(line string) bool, replacement string) (string, bool) {
+	lines := strings.SplitAfter(content, "\n")
+	last := -1
+	for i, line := range lines {
+		if matches(line) {
+			last = i
+		}
+	}
+	if last == -1 {
+		return content, false
+	}
+
+	var out strings.Builder
+	out.Grow(len(content))
+	for i, line := range lines {
+		switch {
+		case i == last:
+			out.WriteString(replacement)
+		case matches(line):
+			// superseded duplicate of the entry being rewritten
+		default:
+			out.WriteString(line)
+		}
+	}
+	return out.String(), true
+}
+
+// replaceFile atomically overwrites an existing file with content, keeping its permissions
+func replaceFile(fileName string, content []byte) error {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return fmt.Errorf("cannot stat %s: %w", fileName, err)
+	}
+	return WriteFileAtomic(fileName, content, info.Mode().Perm())
+}
+
+// UpdateHabitLog replaces the recorded entry for a habit on a day. An entry
+// recorded under one of the habit's former names in aliases is replaced too,
+// and rewritten under the current name.
+func UpdateHabitLog(logDir string, d civil.Date, habit string, result string, comment string, amount string, timeOfDay string, aliases ...string) error {
+	return rewriteLog(logDir, DailyHabit{Day: d, Habit: habit}, aliases, formatLogEntry(d, habit, result, comment, amount, timeOfDay))
+}
+
+// DeleteHabitLog removes the recorded entry for a habit on a day, including
+// one recorded under one of the habit's former names in aliases
+func DeleteHabitLog(logDir string, d civil.Date, habit string, aliases ...string) error {
+	return rewriteLog(logDir, DailyHabit{Day: d, Habit: habit}, aliases, "")
+}
+
+// ResolveAliases moves entries recorded under a habit's former names to its
+// current name, so a renamed habit keeps its history. When a day has entries
+// under both, the one under the current name is kept.
+func (e *Entries) ResolveAliases(habits []*Habit) {
+	current := map[string]string{}
+	for _, habit := range habits {
+		for _, alias := range habit.Aliases {
+			current[alias] = habit.Name
+		}
+	}
+	if len(current) == 0 {
+		return
+	}
+
+	for _, key := range SortedKeys(*e) {
+		name, ok := current[key.Habit]
+		if !ok {
+			continue
+		}
+		outcome := (*e)[key]
+		delete(*e, key)
+		renamed := DailyHabit{Day: key.Day, Habit: name}
+		if _, exists := (*e)[renamed]; !exists {
+			(*e)[renamed] = outcome
+		}
+	}
+}
+
+// FirstRecords sets the FirstRecord field for habits based on their earliest entries,
+// or to their start date for habits that have one. Habits to avoid build their
+// streak without being logged, so one never logged starts on the first day of
+// the log instead.
 func (e *Entries) FirstRecords(from civil.Date, to civil.Date, habits []*Habit) {
 	for dt := to; !dt.Before(from); dt = dt.AddDays(-1) {
 		for _, habit := range habits {
@@ -180,4 +429,24 @@ func (e *Entries) FirstRecords(from civil.Date, to civil.Date, habits []*Habit)
 			}
 		}
 	}
+
+	var firstLogged civil.Date
+	logged := map[string]bool{}
+	for key := range *e {
+		if key.Day.Before(from) || key.Day.After(to) {
+			continue
+		}
+		logged[key.Habit] = true
+		if firstLogged.IsZero() || key.Day.Before(firstLogged) {
+			firstLogged = key.Day
+		}
+	}
+	for _, habit := range habits {
+		switch {
+		case !habit.StartRecord.IsZero():
+			habit.FirstRecord = habit.StartRecord
+		case habit.Avoid && !logged[habit.Name]:
+			habit.FirstRecord = firstLogged
+		}
+	}
 }
@@ -0,0 +1,292 @@
+package storage
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+// Loop Habit Tracker checkmark values. Days where a habit's frequency was
+// already met are marked done automatically; in harsh they are plain days
+// off, as the frequency is worked out again from the entries.
+const (
+	loopUnknown   = -1
+	loopNo        = 0
+	loopYesAuto   = 1
+	loopYesManual = 2
+	loopSkip      = 3
+)
+
+// loopHabitDir matches the per-habit directories of a Loop export, e.g. "001 Meditate"
+var loopHabitDir = regexp.MustCompile(`^(\d+) (.*)$`)
+
+// loopFrequencyPattern matches a frequency as Loop words it, e.g.
+// "3 times per week", "twice a month" or "every 2 days"
+var loopFrequencyPattern = regexp.MustCompile(`^(?:(\d+|once|twice) (?:times? )?(?:per|a|every)|every)(?: (\d+))? (day|week|month)s?$`)
+
+// ReadLoopExport reads the habits and checkmarks of a Loop Habit Tracker CSV
+// export, either the zip file Loop writes or the directory it unzips to.
+// Habits come from Habits.csv and each habit's history from the
+// Checkmarks.csv in its numbered directory.
+func ReadLoopExport(exportPath string) ([]*ImportedHabit, error) {
+	info, err := os.Stat(exportPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Loop export %s: %w", exportPath, err)
+	}
+	if info.IsDir() {
+		return readLoopExport(os.DirFS(exportPath), exportPath)
+	}
+	z, err := zip.OpenReader(exportPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Loop export %s: %w", exportPath, err)
+	}
+	defer z.Close()
+	return readLoopExport(z, exportPath)
+}
+
+// readLoopExport reads a Loop export from fsys. Some zips hold the export in
+// a single top level directory rather than at the root.
+func readLoopExport(fsys fs.FS, exportPath string) ([]*ImportedHabit, error) {
+	root := "."
+	if _, err := fs.Stat(fsys, "Habits.csv"); errors.Is(err, fs.ErrNotExist) {
+		dirs, _ := fs.ReadDir(fsys, ".")
+		if len(dirs) == 1 && dirs[0].IsDir() {
+			root = dirs[0].Name()
+		}
+	}
+
+	habitsFile := path.Join(root, "Habits.csv")
+	rows, err := readCSV(fsys, habitsFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s in Loop export %s: %w", habitsFile, exportPath, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no habits in %s of Loop export %s", habitsFile, exportPath)
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	column := func(row []string, names ...string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+		}
+		return ""
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("%s in Loop export %s has no Name column", habitsFile, exportPath)
+	}
+
+	// Habit directories are named by position, e.g. "001 Meditate"
+	dirs := map[int]string{}
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Loop export %s: %w", exportPath, err)
+	}
+	for _, e := range entries {
+		if m := loopHabitDir.FindStringSubmatch(e.Name()); m != nil && e.IsDir() {
+			position, _ := strconv.Atoi(m[1])
+			dirs[position] = path.Join(root, e.Name())
+		}
+	}
+
+	var habits []*ImportedHabit
+	for i, row := range rows[1:] {
+		line := i + 2
+		name := column(row, "name")
+		if name == "" {
+			continue
+		}
+
+		frequency, err := loopHabitFrequency(column(row, "frequencynumerator", "numrepetitions"),
+			column(row, "frequencydenominator", "interval"), column(row, "frequency"))
+		if err != nil {
+			return nil, &ParseError{File: habitsFile, Line: line, Text: strings.Join(row, ","), Reason: err.Error()}
+		}
+		ih := &ImportedHabit{
+			Habit:   Habit{Name: name, Frequency: frequency},
+			Entries: map[civil.Date]Outcome{},
+		}
+
+		position, err := strconv.Atoi(column(row, "position"))
+		if err != nil {
+			position = i + 1
+		}
+		if dir, ok := dirs[position]; ok {
+			numerical := column(row, "type") == "1"
+			if err := readLoopCheckmarks(fsys, path.Join(dir, "Checkmarks.csv"), numerical, ih.Entries); err != nil {
+				return nil, err
+			}
+		}
+
+		// Archived habits are retired on their last recorded day
+		if strings.EqualFold(column(row, "archived?", "archived"), "true") {
+			for day := range ih.Entries {
+				if day.After(ih.Habit.EndRecord) {
+					ih.Habit.EndRecord = day
+				}
+			}
+		}
+		habits = append(habits, ih)
+	}
+	return habits, nil
+}
+
+// readLoopCheckmarks adds the outcomes in one habit's Checkmarks.csv to
+// entries. Numerical habits record the amount in thousandths, as Loop
+// stores it, and count as done for any amount above zero.
+func readLoopCheckmarks(fsys fs.FS, name string, numerical bool, entries map[civil.Date]Outcome) error {
+	rows, err := readCSV(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read %s in Loop export: %w", name, err)
+	}
+
+	today := civil.DateOf(time.Now())
+	for i, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		day, err := civil.ParseDate(strings.TrimSpace(row[0]))
+		if err != nil {
+			// Header row
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(row[1]))
+		if err != nil {
+			return &ParseError{File: name, Line: i + 1, Text: strings.Join(row, ","), Reason: "invalid checkmark value"}
+		}
+		if day.After(today) {
+			continue
+		}
+
+		if numerical {
+			if value == loopUnknown {
+				continue
+			}
+			if value <= 0 {
+				entries[day] = Outcome{Result: "n"}
+			} else {
+				entries[day] = Outcome{Result: "y", Amount: float64(value) / 1000}
+			}
+			continue
+		}
+		switch value {
+		case loopYesManual:
+			entries[day] = Outcome{Result: "y"}
+		case loopSkip:
+			entries[day] = Outcome{Result: "s"}
+		case loopNo, loopYesAuto:
+			entries[day] = Outcome{Result: "n"}
+		case loopUnknown:
+		default:
+			return &ParseError{File: name, Line: i + 1, Text: strings.Join(row, ","), Reason: "invalid checkmark value"}
+		}
+	}
+	return nil
+}
+
+// readCSV reads every row of a CSV file in fsys
+func readCSV(fsys fs.FS, name string) ([][]string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	var rows [][]string
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// loopHabitFrequency converts a Loop frequency, given as the number of
+// repetitions in an interval of days or in words, to a harsh frequency
+func loopHabitFrequency(repetitions string, interval string, words string) (string, error) {
+	if repetitions != "" && interval != "" {
+		times, err1 := strconv.Atoi(repetitions)
+		days, err2 := strconv.Atoi(interval)
+		if err1 != nil || err2 != nil || times < 1 || days < 1 {
+			return "", fmt.Errorf("invalid frequency %s/%s", repetitions, interval)
+		}
+		return harshFrequency(times, days), nil
+	}
+	if words != "" {
+		return ParseLoopFrequency(words)
+	}
+	return "", fmt.Errorf("habit has no frequency")
+}
+
+// ParseLoopFrequency converts a frequency as Loop words it, such as
+// "3 times per week", "once a month", "every 2 days" or "daily", to a harsh
+// frequency like "3/7". A month is taken as 30 days, as Loop does.
+func ParseLoopFrequency(s string) (string, error) {
+	words := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	switch words {
+	case "daily":
+		return "1", nil
+	case "weekly":
+		return "7", nil
+	case "monthly":
+		return "30", nil
+	}
+
+	m := loopFrequencyPattern.FindStringSubmatch(words)
+	if m == nil {
+		return "", fmt.Errorf("unrecognised frequency %q", s)
+	}
+	times := 1
+	switch m[1] {
+	case "", "once":
+	case "twice":
+		times = 2
+	default:
+		times, _ = strconv.Atoi(m[1])
+	}
+	count := 1
+	if m[2] != "" {
+		count, _ = strconv.Atoi(m[2])
+	}
+	days := map[string]int{"day": 1, "week": 7, "month": 30}[m[3]] * count
+	if times < 1 || days < 1 {
+		return "", fmt.Errorf("unrecognised frequency %q", s)
+	}
+	return harshFrequency(times, days), nil
+}
+
+// harshFrequency formats times in days as a harsh frequency, "1" for daily,
+// "7" for once a week and "3/7" for three times a week
+func harshFrequency(times int, days int) string {
+	if times >= days {
+		return "1"
+	}
+	if times == 1 {
+		return strconv.Itoa(days)
+	}
+	return fmt.Sprintf("%d/%d", times, days)
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MergeConflict is a habit and day recorded with different outcomes in the
+// log and in a conflicted copy of it
+type MergeConflict struct {
+	Key           DailyHabit
+	Current       Outcome // Outcome already in the merged log
+	CurrentSource string  // File the current outcome came from
+	Incoming      Outcome // Differing outcome from the conflicted copy
+	Source        string  // File the incoming outcome came from
+}
+
+// MergePlan lists what merging conflicted copies into the log would change
+type MergePlan struct {
+	Additions map[DailyHabit]Outcome // Entries missing from the log
+	Sources   map[DailyHabit]string  // File each addition came from
+	Conflicts []MergeConflict        // Entries that need a decision
+	local     Entries
+	logged    Entries // Entries in the log file itself, rather than its archives
+}
+
+// FindConflictedLogs returns the sync conflicted copies of the log in logDir,
+// as left behind by Dropbox, Nextcloud ("log (conflicted copy ...)") and
+// Syncthing ("log.sync-conflict-...")
+func FindConflictedLogs(logDir string) ([]string, error) {
+	files, err := os.ReadDir(logDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicted []string
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		name := f.Name()
+		lower := strings.ToLower(name)
+		if (strings.HasPrefix(lower, "log (") && strings.Contains(lower, "conflicted copy")) ||
+			strings.HasPrefix(lower, "log.sync-conflict-") {
+			conflicted = append(conflicted, filepath.Join(logDir, name))
+		}
+	}
+	sort.Strings(conflicted)
+	return conflicted, nil
+}
+
+// PlanMerge unions the entries of the log files at paths into local, the
+// entries of the log at localSource and any of its archives loaded. Entries
+// only in another file become additions; the same habit and day with a
+// different answer becomes a conflict. Entries differing only in their time
+// of day are the same answer, and the one already merged is kept. Files are
+// merged in the order given. Entries under a former name of one of habits are
+// merged as the current name.
+func PlanMerge(local Entries, localSource string, paths []string, habits []*Habit) (*MergePlan, []*ParseError, error) {
+	// Its malformed lines were reported when the log was loaded
+	logged, _, err := LoadLogFile(localSource)
+	if err != nil {
+		return nil, nil, err
+	}
+	logged.ResolveAliases(habits)
+	plan := &MergePlan{
+		Additions: map[DailyHabit]Outcome{},
+		Sources:   map[DailyHabit]string{},
+		local:     local,
+		logged:    *logged,
+	}
+
+	var warnings []*ParseError
+	for _, source := range paths {
+		other, fileWarnings, err := LoadLogFile(source)
+		warnings = append(warnings, fileWarnings...)
+		if err != nil {
+			return nil, warnings, err
+		}
+		other.ResolveAliases(habits)
+		for _, key := range SortedKeys(*other) {
+			incoming := (*other)[key]
+			if current, ok := local[key]; ok {
+				if !sameAnswer(current, incoming) {
+					plan.Conflicts = append(plan.Conflicts, MergeConflict{Key: key, Current: current, CurrentSource: localSource, Incoming: incoming, Source: source})
+				}
+				continue
+			}
+			if current, ok := plan.Additions[key]; ok {
+				if !sameAnswer(current, incoming) {
+					plan.Conflicts = append(plan.Conflicts, MergeConflict{Key: key, Current: current, CurrentSource: plan.Sources[key], Incoming: incoming, Source: source})
+				}
+				continue
+			}
+			plan.Additions[key] = incoming
+			plan.Sources[key] = source
+		}
+	}
+	return plan, warnings, nil
+}
+
+// sameAnswer reports whether two outcomes differ at most in their time of day
+func sameAnswer(a Outcome, b Outcome) bool {
+	a.Time, b.Time = "", ""
+	return a == b
+}
+
+// Apply writes the merge through the repository. choose is called for each
+// conflict and returns the outcome to keep, and every conflict is decided
+// before anything is written. A conflict with an entry only in an archive is
+// appended to the log, which wins over its archives. Returns the number of
+// entries added to and updated in the log.
+func (p *MergePlan) Apply(repository Repository, choose func(c MergeConflict) (Outcome, error)) (added int, updated int, err error) {
+	additions := make(map[DailyHabit]Outcome, len(p.Additions))
+	for key, outcome := range p.Additions {
+		additions[key] = outcome
+	}
+	updates := map[DailyHabit]Outcome{}
+
+	for _, c := range p.Conflicts {
+		chosen, err := choose(c)
+		if err != nil {
+			return 0, 0, err
+		}
+		current, inLocal := p.local[c.Key]
+		_, inLog := p.logged[c.Key]
+		switch {
+		case !inLocal:
+			additions[c.Key] = chosen
+		case chosen == current:
+			delete(updates, c.Key)
+			delete(additions, c.Key)
+		case inLog:
+			updates[c.Key] = chosen
+		default:
+			additions[c.Key] = chosen
+		}
+	}
+
+	for _, key := range SortedKeys(additions) {
+		o := additions[key]
+		if err := repository.WriteEntry(key.Day, key.Habit, o.Result, o.Comment, o.AmountString(), o.Time); err != nil {
+			return added, updated, err
+		}
+		added++
+	}
+	for _, key := range SortedKeys(updates) {
+		o := updates[key]
+		if err := repository.UpdateEntry(key.Day, key.Habit, o.Result, o.Comment, o.AmountString(), o.Time); err != nil {
+			return added, updated, err
+		}
+		updated++
+	}
+	return added, updated, nil
+}
+
+// SortedKeys returns the entry keys ordered by date, then habit name
+func SortedKeys[T any](m map[DailyHabit]T) []DailyHabit {
+	keys := make([]DailyHabit, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Day != keys[j].Day {
+			return keys[i].Day.Before(keys[j].Day)
+		}
+		return keys[i].Habit < keys[j].Habit
+	})
+	return keys
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/civil"
+)
+
+// orgHeadline matches an org headline, capturing its stars, optional TODO
+// keyword and title, without a priority cookie or trailing tags
+var orgHeadline = regexp.MustCompile(`^(\*+)\s+(?:(TODO|NEXT|DONE|CANCELLED)\s+)?(?:\[#[A-Z]\]\s+)?(.*?)(?:\s+:[\w@#%:]+:)?\s*$`)
+
+// orgRepeaterPattern matches the repeater of an org timestamp, e.g. ".+2d/3d"
+var orgRepeaterPattern = regexp.MustCompile(`[.+]?\+(\d+)([dwmy])(?:/(\d+)([dwmy]))?`)
+
+// orgStateChange matches a logbook state change,
+// e.g. `- State "DONE"       from "TODO"       [2025-01-03 Fri 07:30] \\`
+var orgStateChange = regexp.MustCompile(`^\s*-\s+State\s+"([^"]+)"\s+from\s+(?:"[^"]*"\s+)?\[(\d{4}-\d{2}-\d{2})[^\]\d]*(\d{1,2}:\d{2})?[^\]]*\](\s*\\\\)?\s*$`)
+
+// orgClosed matches the CLOSED timestamp of a finished headline
+var orgClosed = regexp.MustCompile(`CLOSED:\s*\[(\d{4}-\d{2}-\d{2})`)
+
+// orgResults maps logbook states to log results. DONE is what org-habit
+// records; SKIP and MISSED are written by harsh export org.
+var orgResults = map[string]string{"DONE": "y", "SKIP": "s", "CANCELLED": "s", "MISSED": "n"}
+
+// orgUnitDays is the number of days in each org repeater unit
+var orgUnitDays = map[string]int{"d": 1, "w": 7, "m": 30, "y": 365}
+
+// orgHabit collects a habit headline while its body is read
+type orgHabit struct {
+	imported *ImportedHabit
+	level    int
+	keyword  string
+	habit    bool // STYLE habit, a repeater or a HARSH_FREQUENCY property
+	repeater []string
+	closed   civil.Date
+	props    map[string]string
+}
+
+// ReadOrgFile reads habits and their history from an org-mode file, as
+// written by harsh export org or kept for org-habit. Headlines with STYLE
+// habit, a repeating SCHEDULED timestamp or a HARSH_FREQUENCY property are
+// habits; other headlines are headings for the habits below them. Logbook
+// state changes become entries: DONE is y, SKIP or CANCELLED s and MISSED n,
+// with any note as the comment and an "Amount: " note line as the amount.
+// Without a HARSH_FREQUENCY property, the repeater gives the frequency, at
+// the most days it allows between completions. HARSH_START and HARSH_END
+// properties give the habit's start and end dates; without HARSH_END, a DONE
+// habit ends on the date it was closed.
+func ReadOrgFile(orgPath string) ([]*ImportedHabit, error) {
+	file, err := os.Open(orgPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open org file %s: %w", orgPath, err)
+	}
+	defer file.Close()
+
+	var habits []*ImportedHabit
+	var current *orgHabit
+	var note *[]string
+	var noteKey civil.Date
+	headings := map[int]string{}
+
+	finish := func() error {
+		if current == nil || !current.habit {
+			return nil
+		}
+		// A habit is not a heading for the headlines below it
+		delete(headings, current.level)
+		h := &current.imported.Habit
+		if freq, ok := current.props["HARSH_FREQUENCY"]; ok {
+			h.Frequency = freq
+		} else if current.repeater != nil {
+			h.Frequency = orgFrequency(current.repeater)
+		} else {
+			h.Frequency = "1"
+		}
+		if start, ok := current.props["HARSH_START"]; ok {
+			d, err := civil.ParseDate(start)
+			if err != nil {
+				return fmt.Errorf("habit %q in %s has an invalid HARSH_START date %q", h.Name, orgPath, start)
+			}
+			h.StartRecord = d
+		}
+		if end, ok := current.props["HARSH_END"]; ok {
+			d, err := civil.ParseDate(end)
+			if err != nil {
+				return fmt.Errorf("habit %q in %s has an invalid HARSH_END date %q", h.Name, orgPath, end)
+			}
+			h.EndRecord = d
+		} else if current.keyword == "DONE" && !current.closed.IsZero() {
+			h.EndRecord = current.closed
+		}
+		habits = append(habits, current.imported)
+		return nil
+	}
+	endNote := func() {
+		if note == nil {
+			return
+		}
+		o := current.imported.Entries[noteKey]
+		var comment []string
+		for _, line := range *note {
+			if amount, ok := strings.CutPrefix(line, "Amount: "); ok {
+				if v, err := strconv.ParseFloat(amount, 64); err == nil {
+					o.Amount = v
+					continue
+				}
+			}
+			comment = append(comment, line)
+		}
+		o.Comment = strings.TrimSpace(strings.Join(comment, "\n"))
+		current.imported.Entries[noteKey] = o
+		note = nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineCount := 0
+	drawer := ""
+	for scanner.Scan() {
+		lineCount++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if m := orgHeadline.FindStringSubmatch(line); m != nil {
+			endNote()
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			level := len(m[1])
+			for l := range headings {
+				if l >= level {
+					delete(headings, l)
+				}
+			}
+			heading := ""
+			for l := level - 1; l >= 1 && heading == ""; l-- {
+				heading = headings[l]
+			}
+			headings[level] = m[3]
+			current = &orgHabit{
+				imported: &ImportedHabit{Habit: Habit{Name: m[3], Heading: heading}, Entries: map[civil.Date]Outcome{}},
+				level:    level,
+				keyword:  m[2],
+				props:    map[string]string{},
+			}
+			drawer = ""
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch {
+		case strings.EqualFold(trimmed, ":END:"):
+			endNote()
+			drawer = ""
+			continue
+		case trimmed == ":PROPERTIES:" || trimmed == ":LOGBOOK:":
+			drawer = trimmed
+			continue
+		case drawer == ":PROPERTIES:":
+			if key, value, ok := strings.Cut(strings.TrimPrefix(trimmed, ":"), ":"); ok {
+				key = strings.ToUpper(key)
+				value = strings.TrimSpace(value)
+				current.props[key] = value
+				if (key == "STYLE" && value == "habit") || key == "HARSH_FREQUENCY" {
+					current.habit = true
+				}
+			}
+			continue
+		}
+
+		if m := orgStateChange.FindStringSubmatch(line); m != nil {
+			endNote()
+			result, ok := orgResults[m[1]]
+			if !ok {
+				continue
+			}
+			d, err := civil.ParseDate(m[2])
+			if err != nil {
+				return nil, &ParseError{File: orgPath, Line: lineCount, Text: line, Reason: "invalid date"}
+			}
+			// Logbooks list the newest change first, which wins for the day
+			if _, seen := current.imported.Entries[d]; seen {
+				continue
+			}
+			o := Outcome{Result: result}
+			if m[3] != "" {
+				if t, err := ParseTimeOfDay(m[3]); err == nil {
+					o.Time = t
+				}
+			}
+			current.imported.Entries[d] = o
+			if m[4] != "" {
+				note = &[]string{}
+				noteKey = d
+			}
+			continue
+		}
+		if note != nil {
+			if trimmed == "" || strings.HasPrefix(trimmed, "- ") {
+				endNote()
+			} else {
+				*note = append(*note, trimmed)
+				continue
+			}
+		}
+
+		if strings.Contains(line, "SCHEDULED:") {
+			if m := orgRepeaterPattern.FindStringSubmatch(line[strings.Index(line, "SCHEDULED:"):]); m != nil {
+				current.repeater = m[1:]
+				current.habit = true
+			}
+		}
+		if m := orgClosed.FindStringSubmatch(line); m != nil {
+			if d, err := civil.ParseDate(m[1]); err == nil {
+				current.closed = d
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading org file %s: %w", orgPath, err)
+	}
+	endNote()
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return habits, nil
+}
+
+// orgFrequency converts an org repeater, as the count and unit of the least
+// and optionally most time between completions, to a harsh frequency
+func orgFrequency(repeater []string) string {
+	count, unit := repeater[0], repeater[1]
+	if repeater[2] != "" {
+		count, unit = repeater[2], repeater[3]
+	}
+	n, _ := strconv.Atoi(count)
+	return harshFrequency(1, max(1, n)*orgUnitDays[unit])
+}
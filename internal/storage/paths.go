@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+)
+
+// profilePattern matches the names profiles can be given
+var profilePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Paths are the directories harsh keeps a profile's files in
+type Paths struct {
+	Profile   string // Named profile, empty for the default one
+	ConfigDir string // Holds the habits and settings files
+	LogDir    string // Holds the log and its archives
+}
+
+// FindPaths works out where the habits, settings and log files of a profile
+// live. HARSHPATH holds all of them when set. Otherwise they are in harsh
+// under XDG_CONFIG_HOME, %APPDATA% on Windows or ~/.config, except that a
+// log already kept in harsh under XDG_DATA_HOME or ~/.local/share is used
+// from there. Named profiles live in a profiles/<name> directory below each.
+func FindPaths(profile string) (Paths, error) {
+	if profile != "" && !profilePattern.MatchString(profile) {
+		return Paths{}, fmt.Errorf("invalid profile name %q (use letters, digits, '-', '_' and '.')", profile)
+	}
+
+	if harshPath := os.Getenv("HARSHPATH"); harshPath != "" {
+		dir := profileDir(harshPath, profile)
+		return Paths{Profile: profile, ConfigDir: dir, LogDir: dir}, nil
+	}
+
+	paths := Paths{Profile: profile, ConfigDir: profileDir(configBaseDir(), profile)}
+	paths.LogDir = paths.ConfigDir
+	if dataBase := dataBaseDir(); dataBase != "" {
+		dataDir := profileDir(dataBase, profile)
+		if _, err := os.Stat(filepath.Join(dataDir, "log")); err == nil {
+			paths.LogDir = dataDir
+		}
+	}
+	return paths, nil
+}
+
+// Profiles returns the names of the profiles that have been created
+func Profiles() []string {
+	base := os.Getenv("HARSHPATH")
+	if base == "" {
+		base = configBaseDir()
+	}
+	dirs, err := os.ReadDir(filepath.Join(base, "profiles"))
+	if err != nil {
+		return nil
+	}
+	var profiles []string
+	for _, dir := range dirs {
+		if dir.IsDir() && profilePattern.MatchString(dir.Name()) {
+			profiles = append(profiles, dir.Name())
+		}
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// configBaseDir returns the os relevant harsh config directory
+func configBaseDir() string {
+	if dir := xdgDir("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "harsh")
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "harsh")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config/harsh")
+}
+
+// dataBaseDir returns the harsh data directory a log may be kept in, or
+// empty where there is none
+func dataBaseDir() string {
+	if dir := xdgDir("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "harsh")
+	}
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	return filepath.Join(os.Getenv("HOME"), ".local/share/harsh")
+}
+
+// xdgDir returns an XDG base directory from the environment. The XDG spec
+// has relative paths ignored, as if the variable were unset.
+func xdgDir(env string) string {
+	dir := os.Getenv(env)
+	if !filepath.IsAbs(dir) {
+		return ""
+	}
+	return dir
+}
+
+// profileDir returns the directory of profile under base
+func profileDir(base string, profile string) string {
+	if profile == "" {
+		return base
+	}
+	return filepath.Join(base, "profiles", profile)
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/civil"
+)
+
+// Pause is a range of days, both included, that habits are paused for, as
+// when travelling. Paused days count as skipped without anything being logged.
+type Pause struct {
+	From civil.Date
+	To   civil.Date
+}
+
+// ParsePause parses a pause range "YYYY-MM-DD..YYYY-MM-DD", or a single
+// paused day "YYYY-MM-DD"
+func ParsePause(s string) (Pause, error) {
+	fromStr, toStr, isRange := strings.Cut(strings.TrimSpace(s), "..")
+	if !isRange {
+		toStr = fromStr
+	}
+	from, err := civil.ParseDate(strings.TrimSpace(fromStr))
+	if err != nil {
+		return Pause{}, fmt.Errorf("invalid pause %q (expected format: YYYY-MM-DD..YYYY-MM-DD)", s)
+	}
+	to, err := civil.ParseDate(strings.TrimSpace(toStr))
+	if err != nil {
+		return Pause{}, fmt.Errorf("invalid pause %q (expected format: YYYY-MM-DD..YYYY-MM-DD)", s)
+	}
+	if to.Before(from) {
+		return Pause{}, fmt.Errorf("invalid pause %q, it ends before it starts", s)
+	}
+	return Pause{From: from, To: to}, nil
+}
+
+// parsePauses parses the comma separated pause ranges of a pause attribute
+func parsePauses(s string) ([]Pause, error) {
+	var pauses []Pause
+	for _, field := range strings.Split(s, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		pause, err := ParsePause(field)
+		if err != nil {
+			return pauses, err
+		}
+		pauses = append(pauses, pause)
+	}
+	return pauses, nil
+}
+
+// String renders the pause as it is written in the habits file
+func (p Pause) String() string {
+	if p.From == p.To {
+		return p.From.String()
+	}
+	return p.From.String() + ".." + p.To.String()
+}
+
+// Contains returns true if d is one of the paused days
+func (p Pause) Contains(d civil.Date) bool {
+	return !d.Before(p.From) && !d.After(p.To)
+}
+
+// IsPaused returns true if the habit is paused on d, by a pause of its own
+// or one of its habits file or heading
+func (h *Habit) IsPaused(d civil.Date) bool {
+	contains := func(p Pause) bool { return p.Contains(d) }
+	return slices.ContainsFunc(h.Pauses, contains) || slices.ContainsFunc(h.GroupPauses, contains)
+}
+
+// AddPause records a pause in the habits file. With habit names it is added
+// to each habit's pause attribute, in the file the habit is defined in;
+// without, a "pause" line pausing every habit goes ahead of the first
+// heading or habit.
+func AddPause(configDir string, pause Pause, habitNames []string) error {
+	unlock, err := LockConfigDir(configDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	habitsPath := filepath.Join(configDir, "habits")
+	content, err := os.ReadFile(habitsPath)
+	if err != nil {
+		return fmt.Errorf("cannot read habits file %s: %w", habitsPath, err)
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+
+	if len(habitNames) == 0 {
+		at := len(lines)
+		for i, line := range lines {
+			text := strings.TrimSpace(line)
+			if text != "" && text[0] != '#' && !strings.HasPrefix(text, "pause ") {
+				at = i
+				break
+			}
+		}
+		if at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+			lines[at-1] += "\n"
+		}
+		lines = slices.Insert(lines, at, "pause "+pause.String()+"\n")
+		return replaceFile(habitsPath, []byte(strings.Join(lines, "")))
+	}
+
+	// Habits may be defined in files the habits file includes
+	loader, err := loadHabitSources(configDir)
+	if err != nil {
+		return err
+	}
+	files := map[string][]string{habitsPath: lines}
+	var changed []string
+	for _, name := range habitNames {
+		source, ok := loader.defined[name]
+		if !ok {
+			return fmt.Errorf("no habit named %q in your habits file", name)
+		}
+		fileLines, ok := files[source.file]
+		if !ok {
+			content, err := os.ReadFile(source.file)
+			if err != nil {
+				return fmt.Errorf("cannot read habits file %s: %w", source.file, err)
+			}
+			fileLines = strings.SplitAfter(string(content), "\n")
+			files[source.file] = fileLines
+		}
+		if !slices.Contains(changed, source.file) {
+			changed = append(changed, source.file)
+		}
+		if source.line > len(fileLines) {
+			return fmt.Errorf("habits file %s changed while pausing", source.file)
+		}
+
+		line := fileLines[source.line-1]
+		text := strings.TrimRight(line, "\r\n")
+		rest, attrs, err := splitHabitAttributes(text)
+		if err != nil {
+			return fmt.Errorf("cannot add a pause to %q, its attributes are malformed: %w", name, err)
+		}
+		added := false
+		for j, attr := range attrs {
+			if attr[0] == "pause" {
+				attrs[j][1] = strings.TrimSpace(attr[1]) + "," + pause.String()
+				added = true
+			}
+		}
+		if !added {
+			attrs = append(attrs, [2]string{"pause", pause.String()})
+		}
+		rendered := make([]string, len(attrs))
+		for j, attr := range attrs {
+			rendered[j] = attr[0] + "=" + formatAttributeValue(attr[1])
+		}
+		fileLines[source.line-1] = rest + " [" + strings.Join(rendered, "; ") + "]" + line[len(text):]
+	}
+	for _, file := range changed {
+		if err := replaceFile(file, []byte(strings.Join(files[file], ""))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RenameHabit renames a habit in the habits file, or the included file it is
+// defined in, and rewrites its entries in the log and yearly archives to the
+// new name, returning the number of lines changed. The habits file is in
+// configDir and the log in logDir, which may be the same directory. With
+// keepAlias set the log is left as is and the old name is recorded as a
+// former name, "New Name (was: Old Name): ...", so its history still
+// resolves.
+func RenameHabit(configDir string, logDir string, oldName string, newName string, keepAlias bool) (int, error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return 0, fmt.Errorf("no new habit name given")
+	}
+	if err := validateHabitName(newName); err != nil {
+		return 0, err
+	}
+	if newName == oldName {
+		return 0, fmt.Errorf("habit is already named %q", newName)
+	}
+
+	unlock, err := LockConfigDir(configDir)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	if logDir != configDir && !keepAlias {
+		unlockLog, err := LockConfigDir(logDir)
+		if err != nil {
+			return 0, err
+		}
+		defer unlockLog()
+	}
+
+	// The habit may be defined in a file the habits file includes
+	loader, err := loadHabitSources(configDir)
+	if err != nil {
+		return 0, err
+	}
+	source, ok := loader.defined[oldName]
+	if !ok {
+		return 0, fmt.Errorf("no habit named %q in your habits file", oldName)
+	}
+	for _, habit := range loader.habits {
+		if habit.Name != oldName && (habit.Name == newName || slices.Contains(habit.Aliases, newName)) {
+			return 0, fmt.Errorf("a habit named %q is already in your habits file", newName)
+		}
+	}
+
+	content, err := os.ReadFile(source.file)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read habits file %s: %w", source.file, err)
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if source.line > len(lines) {
+		return 0, fmt.Errorf("habits file %s changed while renaming", source.file)
+	}
+	line := lines[source.line-1]
+	text := strings.TrimRight(line, "\r\n")
+	fields, aliases := splitHabitAliases(text)
+	name := splitFields(fields, ": ")[0]
+	// Renaming back to a former name drops it from the former names
+	aliases = slices.DeleteFunc(aliases, func(alias string) bool { return alias == newName })
+	if keepAlias && !slices.Contains(aliases, oldName) {
+		aliases = append(aliases, oldName)
+	}
+	renamed := EscapeField(newName)
+	if len(aliases) > 0 {
+		escaped := make([]string, len(aliases))
+		for j, alias := range aliases {
+			escaped[j] = EscapeField(alias)
+		}
+		renamed += " (was: " + strings.Join(escaped, ", ") + ")"
+	}
+	lines[source.line-1] = renamed + fields[len(name):] + line[len(text):]
+
+	changed := 0
+	if !keepAlias {
+		logPath := filepath.Join(logDir, "log")
+		logContent, err := os.ReadFile(logPath)
+		if err != nil {
+			return 0, fmt.Errorf("cannot read log file %s: %w", logPath, err)
+		}
+		renamedLog, n := renameLogLines(string(logContent), oldName, newName)
+		if n > 0 {
+			if err := replaceFile(logPath, []byte(renamedLog)); err != nil {
+				return 0, err
+			}
+			changed += n
+		}
+
+		// Archived entries are renamed too, so older history is not orphaned
+		archives, err := FindArchives(logDir)
+		if err != nil {
+			return changed, err
+		}
+		for _, paths := range archives {
+			for _, archivePath := range paths {
+				archiveContent, err := readArchiveFile(archivePath)
+				if err != nil {
+					return changed, err
+				}
+				renamedArchive, n := renameLogLines(string(archiveContent), oldName, newName)
+				if n == 0 {
+					continue
+				}
+				if err := writeArchiveFile(archivePath, []byte(renamedArchive)); err != nil {
+					return changed, err
+				}
+				changed += n
+			}
+		}
+	}
+
+	if err := replaceFile(source.file, []byte(strings.Join(lines, ""))); err != nil {
+		return changed, err
+	}
+	return changed, nil
+}
+
+// renameLogLines rewrites the habit name of log lines recorded under oldName,
+// returning the new content and the number of lines changed
+func renameLogLines(content string, oldName string, newName string) (string, int) {
+	changed := 0
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		text := strings.TrimRight(line, "\r\n")
+		key, ok := logLineKey(text)
+		if !ok || key.Habit != oldName {
+			continue
+		}
+		fields := splitFields(text, " : ")
+		fields[1] = EscapeField(newName)
+		lines[i] = strings.Join(fields, " : ") + line[len(text):]
+		changed++
+	}
+	return strings.Join(lines, ""), changed
+}
@@ -1,6 +1,11 @@
 package storage
 
-import "cloud.google.com/go/civil"
+import (
+	"fmt"
+	"sort"
+
+	"cloud.google.com/go/civil"
+)
 
 // Repository defines the interface for data access operations
 type Repository interface {
@@ -9,39 +14,136 @@ type Repository interface {
 	
 	// Log operations
 	LoadEntries() (*Entries, error)
-	WriteEntry(d civil.Date, habit string, result string, comment string, amount string) error
-	
+	LoadHistory(from civil.Date) ([]*ParseError, error)
+	WriteEntry(d civil.Date, habit string, result string, comment string, amount string, timeOfDay string) error
+	UpdateEntry(d civil.Date, habit string, result string, comment string, amount string, timeOfDay string) error
+	DeleteEntry(d civil.Date, habit string) error
+
 	// Configuration
 	GetConfigDir() string
+	GetLogDir() string
 	InitializeConfig() error
 }
 
 // FileRepository implements Repository using file-based storage
 type FileRepository struct {
 	configDir string
+	logDir    string
+	created   bool
+	warnings  []*ParseError
+	habits    []*Habit // Habits last loaded, for resolving former names
+	entries   *Entries // Entries last loaded, which archives are added to
+	archived  map[int]bool
 }
 
-// NewFileRepository creates a new file-based repository
-func NewFileRepository() *FileRepository {
-	configDir := FindConfigFiles()
-	return &FileRepository{configDir: configDir}
+// NewFileRepository creates a new file-based repository in paths
+func NewFileRepository(paths Paths) (*FileRepository, error) {
+	created, err := FindConfigFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	return &FileRepository{configDir: paths.ConfigDir, logDir: paths.LogDir, created: created}, nil
 }
 
 // LoadHabits loads habits from the config file
 func (r *FileRepository) LoadHabits() ([]*Habit, int, error) {
-	habits, maxLength := LoadHabitsConfig(r.configDir)
-	return habits, maxLength, nil
+	habits, maxLength, warnings, err := LoadHabitsConfig(r.configDir)
+	r.warnings = append(r.warnings, warnings...)
+	r.habits = habits
+	return habits, maxLength, err
 }
 
-// LoadEntries loads log entries from the log file
+// LoadEntries loads log entries from the log file, leaving yearly archives to
+// LoadHistory. Once habits are loaded, entries recorded under a habit's former
+// names are moved to its current name.
 func (r *FileRepository) LoadEntries() (*Entries, error) {
-	entries := LoadLog(r.configDir)
+	entries, warnings, err := LoadLog(r.logDir)
+	r.warnings = append(r.warnings, warnings...)
+	if err != nil {
+		return nil, err
+	}
+	entries.ResolveAliases(r.habits)
+	r.entries = entries
+	r.archived = map[int]bool{}
 	return entries, nil
 }
 
+// LoadHistory adds entries from the yearly log archives covering from onwards
+// to the entries returned by LoadEntries, returning malformed archive lines as
+// warnings. A zero from loads every archive. Archives are only read once, and
+// entries in the log win over archived ones.
+func (r *FileRepository) LoadHistory(from civil.Date) ([]*ParseError, error) {
+	if r.entries == nil {
+		return nil, fmt.Errorf("log entries must be loaded before their history")
+	}
+	archives, err := FindArchives(r.logDir)
+	if err != nil {
+		return nil, err
+	}
+
+	years := make([]int, 0, len(archives))
+	for year := range archives {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	var warnings []*ParseError
+	for _, year := range years {
+		if year < from.Year || r.archived[year] {
+			continue
+		}
+		for _, archivePath := range archives[year] {
+			archived, archiveWarnings, err := LoadArchive(archivePath)
+			warnings = append(warnings, archiveWarnings...)
+			if err != nil {
+				return warnings, err
+			}
+			archived.ResolveAliases(r.habits)
+			for key, outcome := range *archived {
+				if _, ok := (*r.entries)[key]; !ok {
+					(*r.entries)[key] = outcome
+				}
+			}
+		}
+		r.archived[year] = true
+	}
+	return warnings, nil
+}
+
+// aliasesOf returns the former names of a loaded habit
+func (r *FileRepository) aliasesOf(habit string) []string {
+	for _, h := range r.habits {
+		if h.Name == habit {
+			return h.Aliases
+		}
+	}
+	return nil
+}
+
+// Warnings returns the malformed lines skipped while loading habits and entries
+func (r *FileRepository) Warnings() []*ParseError {
+	return r.warnings
+}
+
+// Created reports whether the example habits and log files were just created
+func (r *FileRepository) Created() bool {
+	return r.created
+}
+
 // WriteEntry writes a log entry to the log file
-func (r *FileRepository) WriteEntry(d civil.Date, habit string, result string, comment string, amount string) error {
-	return WriteHabitLog(r.configDir, d, habit, result, comment, amount)
+func (r *FileRepository) WriteEntry(d civil.Date, habit string, result string, comment string, amount string, timeOfDay string) error {
+	return WriteHabitLog(r.logDir, d, habit, result, comment, amount, timeOfDay)
+}
+
+// UpdateEntry rewrites the existing log entry for a habit on a day in place,
+// including one recorded under a former name of the habit
+func (r *FileRepository) UpdateEntry(d civil.Date, habit string, result string, comment string, amount string, timeOfDay string) error {
+	return UpdateHabitLog(r.logDir, d, habit, result, comment, amount, timeOfDay, r.aliasesOf(habit)...)
+}
+
+// DeleteEntry removes the log entry for a habit on a day
+func (r *FileRepository) DeleteEntry(d civil.Date, habit string) error {
+	return DeleteHabitLog(r.logDir, d, habit, r.aliasesOf(habit)...)
 }
 
 // GetConfigDir returns the configuration directory
@@ -49,8 +151,13 @@ func (r *FileRepository) GetConfigDir() string {
 	return r.configDir
 }
 
+// GetLogDir returns the directory holding the log and its archives
+func (r *FileRepository) GetLogDir() string {
+	return r.logDir
+}
+
 // InitializeConfig initializes the configuration if needed
 func (r *FileRepository) InitializeConfig() error {
-	// This is handled by FindConfigFiles() which calls welcome() if needed
+	// This is handled by FindConfigFiles() which creates the files if needed
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,302 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// settingsFileName is the optional preferences file in the config dir
+const settingsFileName = "config"
+
+// Settings are the preferences harsh runs with. Each comes from the
+// settings file in the config dir unless a HARSH_ environment variable
+// overrides it, and command line flags override both.
+type Settings struct {
+	Color     string // "auto", "always" or "never"
+	HideEnded bool   // Leave habits with an end date out of listings
+	GraphDays int    // Days of history graphs show, 0 to fit the terminal
+	TodoDays  int    // Days before today that todo lists undone habits for
+	WeekStart string // "monday" or "sunday"
+	Symbols   string // Symbol set graphs are drawn with, "unicode" or "ascii"
+
+	sources map[string]string // Where each setting not at its default came from
+}
+
+// setting describes a key of the settings file
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	values []string // Accepted values, or nil for any the parser accepts
+	get    func(s *Settings) string
+	set    func(s *Settings, value string) error
+}
+
+// settings lists the keys of the settings file, in the order they are listed
+var settings = []setting{
+	{
+		key:    "color",
+		env:    "HARSH_COLOR",
+		usage:  "colors in output",
+		values: []string{"auto", "always", "never"},
+		get:    func(s *Settings) string { return s.Color },
+		set:    func(s *Settings, v string) error { s.Color = v; return nil },
+	},
+	{
+		key:   "hide-ended",
+		env:   "HARSH_HIDE_ENDED",
+		usage: "hide habits that have an end date",
+		get:   func(s *Settings) string { return strconv.FormatBool(s.HideEnded) },
+		set: func(s *Settings, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("expected true or false")
+			}
+			s.HideEnded = b
+			return nil
+		},
+	},
+	{
+		key:   "graph-days",
+		env:   "HARSH_GRAPH_DAYS",
+		usage: "days of history in graphs, 0 to fit the terminal",
+		get:   func(s *Settings) string { return strconv.Itoa(s.GraphDays) },
+		set: func(s *Settings, v string) error {
+			n, err := parseSettingDays(v)
+			if err != nil {
+				return err
+			}
+			s.GraphDays = n
+			return nil
+		},
+	},
+	{
+		key:   "todo-days",
+		env:   "HARSH_TODO_DAYS",
+		usage: "days before today that todo lists undone habits for, at least 1",
+		get:   func(s *Settings) string { return strconv.Itoa(s.TodoDays) },
+		set: func(s *Settings, v string) error {
+			n, err := parseSettingDays(v)
+			if err != nil {
+				return err
+			}
+			// 0 days back is the unfiltered list of every habit shown on onboarding
+			if n < 1 {
+				return fmt.Errorf("expected at least 1 day")
+			}
+			s.TodoDays = n
+			return nil
+		},
+	},
+	{
+		key:    "week-start",
+		env:    "HARSH_WEEK_START",
+		usage:  "first day of the week",
+		values: []string{"monday", "sunday"},
+		get:    func(s *Settings) string { return s.WeekStart },
+		set:    func(s *Settings, v string) error { s.WeekStart = v; return nil },
+	},
+	{
+		key:    "symbols",
+		env:    "HARSH_SYMBOLS",
+		usage:  "symbols graphs are drawn with",
+		values: []string{"unicode", "ascii"},
+		get:    func(s *Settings) string { return s.Symbols },
+		set:    func(s *Settings, v string) error { s.Symbols = v; return nil },
+	},
+}
+
+// SettingsPath returns the path of the settings file in configDir
+func SettingsPath(configDir string) string {
+	return filepath.Join(configDir, settingsFileName)
+}
+
+// DefaultSettings returns the settings harsh uses when nothing overrides them
+func DefaultSettings() Settings {
+	return Settings{
+		Color:     "auto",
+		GraphDays: 0,
+		TodoDays:  8,
+		WeekStart: "monday",
+		Symbols:   "unicode",
+	}
+}
+
+// SettingKeys returns the keys of the settings file
+func SettingKeys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+// SettingUsage returns what a setting controls and the values it accepts
+func SettingUsage(key string) (string, error) {
+	s, err := findSetting(key)
+	if err != nil {
+		return "", err
+	}
+	if s.values != nil {
+		return fmt.Sprintf("%s (%s)", s.usage, strings.Join(s.values, ", ")), nil
+	}
+	return s.usage, nil
+}
+
+// Get returns the value of a setting as it is written in the settings file
+func (s *Settings) Get(key string) (string, error) {
+	def, err := findSetting(key)
+	if err != nil {
+		return "", err
+	}
+	return def.get(s), nil
+}
+
+// Source returns where a setting came from: "default", "config" or the
+// environment variable that set it
+func (s *Settings) Source(key string) string {
+	if source, ok := s.sources[key]; ok {
+		return source
+	}
+	return "default"
+}
+
+// Override sets key to value from a higher precedence source, such as a
+// command line flag, named by source
+func (s *Settings) Override(key string, value string, source string) error {
+	return s.set(key, value, source)
+}
+
+// set parses value into the setting for key, noting where it came from
+func (s *Settings) set(key string, value string, source string) error {
+	def, err := findSetting(key)
+	if err != nil {
+		return err
+	}
+	value = strings.ToLower(strings.TrimSpace(value))
+	if def.values != nil && !slices.Contains(def.values, value) {
+		return fmt.Errorf("invalid %s %q (expected %s)", key, value, strings.Join(def.values, ", "))
+	}
+	if err := def.set(s, value); err != nil {
+		return fmt.Errorf("invalid %s %q (%v)", key, value, err)
+	}
+	if s.sources == nil {
+		s.sources = map[string]string{}
+	}
+	s.sources[key] = source
+	return nil
+}
+
+// LoadSettings reads the settings file in configDir, if there is one, over
+// the defaults, then applies any HARSH_ environment variable overrides.
+// Unknown keys and invalid values in the file are skipped and returned as
+// warnings; an invalid value in the environment is an error.
+func LoadSettings(configDir string) (*Settings, []*ParseError, error) {
+	s := DefaultSettings()
+	settingsPath := SettingsPath(configDir)
+	var warnings []*ParseError
+
+	file, err := os.Open(settingsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("cannot open settings file %s: %w", settingsPath, err)
+	}
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		lineCount := 0
+		for scanner.Scan() {
+			lineCount++
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				warnings = append(warnings, &ParseError{File: settingsPath, Line: lineCount, Text: line, Reason: "skipping malformed setting (expected format: key: value)"})
+				continue
+			}
+			if err := s.set(strings.TrimSpace(key), value, "config"); err != nil {
+				warnings = append(warnings, &ParseError{File: settingsPath, Line: lineCount, Text: line, Reason: err.Error()})
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, warnings, fmt.Errorf("failed reading settings file %s: %w", settingsPath, err)
+		}
+	}
+
+	for _, def := range settings {
+		if value, ok := os.LookupEnv(def.env); ok && value != "" {
+			if err := s.set(def.key, value, def.env); err != nil {
+				return nil, warnings, fmt.Errorf("%s: %w", def.env, err)
+			}
+		}
+	}
+	return &s, warnings, nil
+}
+
+// SetSetting writes key: value to the settings file in configDir, replacing
+// the key's existing line or adding one, and creating the file if needed.
+// Comments and the other settings are left as they are.
+func SetSetting(configDir string, key string, value string) error {
+	var check Settings
+	if err := check.set(key, value, "config"); err != nil {
+		return err
+	}
+	value, _ = check.Get(key)
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return fmt.Errorf("cannot create configuration directory %s: %w", configDir, err)
+	}
+	unlock, err := LockConfigDir(configDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	settingsPath := SettingsPath(configDir)
+	content, err := os.ReadFile(settingsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot read settings file %s: %w", settingsPath, err)
+	}
+
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+	replaced := false
+	for i, line := range lines {
+		k, _, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok && !replaced && strings.TrimSpace(k) == key {
+			lines[i] = key + ": " + value
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, key+": "+value)
+	}
+	return WriteFileAtomic(settingsPath, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// findSetting returns the definition of the setting for key
+func findSetting(key string) (*setting, error) {
+	for i := range settings {
+		if settings[i].key == key {
+			return &settings[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown setting %q (settings are: %s)", key, strings.Join(SettingKeys(), ", "))
+}
+
+// parseSettingDays parses a non-negative number of days
+func parseSettingDays(v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a number of days")
+	}
+	return n, nil
+}
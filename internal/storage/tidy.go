@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TidyResult describes the changes TidyLog makes, or would make, to the log
+type TidyResult struct {
+	Entries     int      // Entries kept in the tidied log
+	Moved       int      // Kept entries moved to put the log in order
+	Duplicates  []string // Superseded lines dropped in favour of a later entry
+	Orphans     []string // Entries for habits missing from the habits file
+	Changed     bool     // Whether the tidied log differs from the original
+	BackupPath  string   // Where the original log was saved, if it was rewritten
+	ArchivePath string   // Where orphaned entries were appended, if any
+	Diff        string   // Unified diff of the original log against the tidied one
+}
+
+// TidyLog rewrites the log sorted by date, then by habit in habits file order.
+// Duplicate entries for the same habit and day collapse to the last one, the
+// same entry LoadLog keeps. Comments and lines LoadLog cannot read stay with
+// the entry that follows them, or at the top or bottom of the file when no
+// entry precedes or follows them. Entries for habits not in the habits file
+// are kept unless archiveOrphans is set, in which case they are appended to
+// log.orphaned, along with their comments. The original log is saved as
+// log.bak. With dryRun set nothing is written and the result only reports
+// the changes.
+func TidyLog(logDir string, habits []*Habit, archiveOrphans bool, dryRun bool) (*TidyResult, error) {
+	unlock, err := LockConfigDir(logDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	fileName := filepath.Join(logDir, "log")
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, fmt.Errorf("permission denied reading log file: %s (check file permissions)", fileName)
+		}
+		return nil, fmt.Errorf("cannot read log file %s: %w", fileName, err)
+	}
+
+	// Former names sort with, and are not orphans of, their current habit
+	habitOrder := make(map[string]int, len(habits))
+	for i, habit := range habits {
+		for _, name := range habit.Names() {
+			habitOrder[name] = i
+		}
+	}
+
+	type logLine struct {
+		text  string
+		index int
+		notes []string // Comments and unreadable lines just before the entry
+	}
+
+	result := &TidyResult{}
+	var header, notes []string
+	latest := map[DailyHabit]logLine{}
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, _, _, ok := parseLogLine(line)
+		if line[0] == '#' || !ok {
+			if len(latest) == 0 {
+				header = append(header, line)
+			} else {
+				notes = append(notes, line)
+			}
+			continue
+		}
+		// The notes of a superseded entry move to the one that replaces it
+		if previous, seen := latest[key]; seen {
+			result.Duplicates = append(result.Duplicates, previous.text)
+			notes = append(previous.notes, notes...)
+		}
+		latest[key] = logLine{text: line, index: i, notes: notes}
+		notes = nil
+	}
+
+	// Keys in the order of their winning line in the original log
+	order := make([]DailyHabit, 0, len(latest))
+	for key := range latest {
+		order = append(order, key)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return latest[order[i]].index < latest[order[j]].index
+	})
+
+	kept := make([]DailyHabit, 0, len(order))
+	var orphans []DailyHabit
+	for _, key := range order {
+		if _, known := habitOrder[key.Habit]; !known && archiveOrphans {
+			orphans = append(orphans, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+
+	sorted := make([]DailyHabit, len(kept))
+	copy(sorted, kept)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Day != b.Day {
+			return a.Day.Before(b.Day)
+		}
+		ai, aKnown := habitOrder[a.Habit]
+		bi, bKnown := habitOrder[b.Habit]
+		switch {
+		case aKnown && bKnown:
+			return ai < bi
+		case aKnown != bKnown:
+			// Habits from the habits file come before orphaned ones
+			return aKnown
+		default:
+			return a.Habit < b.Habit
+		}
+	})
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return latest[orphans[i]].text < latest[orphans[j]].text
+	})
+	var archived strings.Builder
+	for _, key := range orphans {
+		result.Orphans = append(result.Orphans, latest[key].text)
+		for _, note := range latest[key].notes {
+			archived.WriteString(note + "\n")
+		}
+		archived.WriteString(latest[key].text + "\n")
+	}
+
+	// Entries already in order relative to each other stay where they are,
+	// so only those outside the longest such run are moved
+	rank := make(map[DailyHabit]int, len(sorted))
+	for i, key := range sorted {
+		rank[key] = i
+	}
+	ranks := make([]int, len(kept))
+	for i, key := range kept {
+		ranks[i] = rank[key]
+	}
+	result.Moved = len(kept) - longestIncreasing(ranks)
+	result.Entries = len(sorted)
+
+	var out strings.Builder
+	out.Grow(len(content))
+	for _, line := range header {
+		out.WriteString(line + "\n")
+	}
+	for _, key := range sorted {
+		for _, note := range latest[key].notes {
+			out.WriteString(note + "\n")
+		}
+		out.WriteString(latest[key].text + "\n")
+	}
+	for _, line := range notes {
+		out.WriteString(line + "\n")
+	}
+
+	result.Changed = out.String() != string(content)
+	if !result.Changed {
+		return result, nil
+	}
+	result.Diff = unifiedDiff(fileName, fileName+" (tidied)", string(content), out.String())
+	if dryRun {
+		return result, nil
+	}
+
+	// Save the original and archive orphans before touching the log so an
+	// interrupted tidy never loses entries
+	backupPath := fileName + ".bak"
+	if err := WriteFileAtomic(backupPath, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to back up log file to %s: %w", backupPath, err)
+	}
+	result.BackupPath = backupPath
+
+	if len(result.Orphans) > 0 {
+		archivePath := fileName + ".orphaned"
+		f, err := os.OpenFile(archivePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open orphan archive %s: %w", archivePath, err)
+		}
+		if _, err := f.WriteString(archived.String()); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to archive orphaned entries to %s: %w", archivePath, err)
+		}
+		if err := f.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close orphan archive %s: %w", archivePath, err)
+		}
+		result.ArchivePath = archivePath
+	}
+
+	if err := replaceFile(fileName, []byte(out.String())); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// longestIncreasing returns the length of the longest increasing subsequence
+// of distinct numbers
+func longestIncreasing(numbers []int) int {
+	// tails[i] is the smallest last number of an increasing subsequence of
+	// length i+1 found so far
+	var tails []int
+	for _, n := range numbers {
+		i := sort.SearchInts(tails, n)
+		if i == len(tails) {
+			tails = append(tails, n)
+		} else {
+			tails[i] = n
+		}
+	}
+	return len(tails)
+}
@@ -2,6 +2,9 @@ package ui
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -33,6 +36,231 @@ func NewDisplay(noColor bool) *Display {
 	}
 }
 
+// ShowWelcome greets a new user after the example habits and log files are created
+func (d *Display) ShowWelcome(configDir string, logDir string) {
+	fmt.Println("Welcome to harsh!")
+	fmt.Println("Created " + filepath.Join(configDir, "habits") + "   This file lists your habits.")
+	fmt.Println("Created " + filepath.Join(logDir, "log") + "      This file is your habit log.")
+	fmt.Println("")
+	fmt.Println("No habits of your own yet?")
+	fmt.Println("Open your habits file @ " + filepath.Join(configDir, "habits"))
+	fmt.Println("with a text editor (nano, vim, VS Code, Atom, emacs) and modify and save the habits list.")
+	fmt.Println("Then:")
+	fmt.Println("Run       harsh ask     to start tracking")
+	fmt.Println("Running   harsh todo    will show you undone habits for today.")
+	fmt.Println("Running   harsh log     will show you a consistency graph of your efforts.")
+	fmt.Println("                        (the graph gets way cooler looking over time.")
+	fmt.Println("For more depth, you can read https://github.com/wakatara/harsh#usage")
+	fmt.Println("")
+	fmt.Println("Happy tracking! I genuinely hope this helps you with your goals. Buena suerte!")
+}
+
+// ShowWarnings reports lines skipped while loading the habits and log files.
+// Written to stderr so warnings never corrupt piped or --json output.
+func (d *Display) ShowWarnings(warnings []*storage.ParseError) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w.Error())
+	}
+}
+
+// ShowTidyResult summarises a log tidy, listing the lines dropped or archived.
+// With dryRun set it shows the changes a tidy would make as a diff instead.
+func (d *Display) ShowTidyResult(result *storage.TidyResult, dryRun bool) {
+	if !result.Changed {
+		fmt.Println("Log is already tidy.")
+		return
+	}
+
+	if dryRun {
+		d.showDiff(result.Diff)
+	}
+
+	verb := func(done string, planned string) string {
+		if dryRun {
+			return planned
+		}
+		return done
+	}
+
+	if result.Moved > 0 {
+		fmt.Printf("%s %s by date and habit\n", plural(result.Moved, "entry", "entries"), verb("reordered", "would be reordered"))
+	}
+	if len(result.Duplicates) > 0 {
+		fmt.Printf("%s %s:\n", plural(len(result.Duplicates), "superseded duplicate", "superseded duplicates"), verb("removed", "would be removed"))
+		for _, line := range result.Duplicates {
+			d.colorManager.PrintfRed("- %s\n", line)
+		}
+	}
+	if len(result.Orphans) > 0 {
+		fmt.Printf("%s for habits not in your habits file %s:\n", plural(len(result.Orphans), "entry", "entries"), verb("archived to "+result.ArchivePath, "would be archived"))
+		for _, line := range result.Orphans {
+			d.colorManager.PrintfYellow("> %s\n", line)
+		}
+	}
+
+	if dryRun {
+		fmt.Println("Dry run: log not changed.")
+	} else {
+		fmt.Printf("Log tidied, %d entries kept. Original saved to %s\n", result.Entries, result.BackupPath)
+	}
+}
+
+// plural formats a count with the singular or plural form of what it counts
+func plural(n int, one string, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// showDiff prints a unified diff, colouring removed and added lines
+func (d *Display) showDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			d.colorManager.PrintlnBold(line)
+		case strings.HasPrefix(line, "@@"):
+			d.colorManager.PrintfBlue("%s\n", line)
+		case strings.HasPrefix(line, "-"):
+			d.colorManager.PrintfRed("%s\n", line)
+		case strings.HasPrefix(line, "+"):
+			d.colorManager.PrintfGreen("%s\n", line)
+		default:
+			fmt.Println(line)
+		}
+	}
+	fmt.Println()
+}
+
+// ShowMergePlan lists the entries a merge of conflicted log copies would add
+// and the conflicts it would need decided, without changing anything
+func (d *Display) ShowMergePlan(plan *storage.MergePlan) {
+	if len(plan.Additions) == 0 && len(plan.Conflicts) == 0 {
+		fmt.Println("Nothing to merge, the log already has every entry.")
+		return
+	}
+
+	if len(plan.Additions) > 0 {
+		fmt.Printf("%d entries would be added:\n", len(plan.Additions))
+		for _, key := range storage.SortedKeys(plan.Additions) {
+			d.colorManager.PrintfGreen("+ %s : %s : %s\n", key.Day, key.Habit, plan.Additions[key].Result)
+		}
+	}
+	if len(plan.Conflicts) > 0 {
+		fmt.Printf("%d conflicts would need a decision:\n", len(plan.Conflicts))
+		for _, c := range plan.Conflicts {
+			d.colorManager.PrintfYellow("! %s : %s : %s (%s) vs %s (%s)\n",
+				c.Key.Day, c.Key.Habit,
+				c.Current.Result, filepath.Base(c.CurrentSource),
+				c.Incoming.Result, filepath.Base(c.Source))
+		}
+	}
+	fmt.Println("Dry run: log not changed.")
+}
+
+// ShowArchiveResult summarises the entries moved out of the log into yearly
+// archives. With dryRun set it describes what archiving would do instead.
+func (d *Display) ShowArchiveResult(result *storage.ArchiveResult, before civil.Date, dryRun bool) {
+	if result.Moved == 0 {
+		fmt.Printf("No log entries before %s to archive.\n", before)
+		return
+	}
+
+	years := make([]int, 0, len(result.Files))
+	for year := range result.Files {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	files := make([]string, len(years))
+	for i, year := range years {
+		files[i] = filepath.Base(result.Files[year])
+	}
+
+	if dryRun {
+		fmt.Printf("%d entries before %s would be archived to %s\n", result.Moved, before, strings.Join(files, ", "))
+		fmt.Println("Dry run: log not changed.")
+	} else {
+		fmt.Printf("Archived %d entries before %s to %s\n", result.Moved, before, strings.Join(files, ", "))
+	}
+}
+
+// ShowImportPlan lists the habits an import would add or log under existing
+// habits and how many entries it would write, without changing anything
+func (d *Display) ShowImportPlan(plan *storage.ImportPlan) {
+	if len(plan.NewHabits) > 0 {
+		fmt.Printf("%d habits would be added to your habits file:\n", len(plan.NewHabits))
+		for _, habit := range plan.NewHabits {
+			d.colorManager.PrintfGreen("+ %s\n", storage.FormatHabit(habit))
+		}
+	}
+	if len(plan.Matched) > 0 {
+		fmt.Printf("%d habits would be logged under existing habits:\n", len(plan.Matched))
+		names := make([]string, 0, len(plan.Matched))
+		for name := range plan.Matched {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if plan.Matched[name] == name {
+				fmt.Printf("= %s\n", name)
+			} else {
+				fmt.Printf("= %s -> %s\n", name, plan.Matched[name])
+			}
+		}
+	}
+
+	counts := map[string]int{}
+	for key := range plan.Additions {
+		counts[key.Habit]++
+	}
+	habits := make([]string, 0, len(counts))
+	for habit := range counts {
+		habits = append(habits, habit)
+	}
+	sort.Strings(habits)
+	fmt.Printf("%d entries would be added", len(plan.Additions))
+	if plan.Existing > 0 {
+		fmt.Printf(" (%d days already logged are kept)", plan.Existing)
+	}
+	if len(habits) == 0 {
+		fmt.Println(".")
+	} else {
+		fmt.Println(":")
+	}
+	for _, habit := range habits {
+		fmt.Printf("  %s: %d\n", habit, counts[habit])
+	}
+	fmt.Println("Dry run: habits and log not changed.")
+}
+
+// ShowSettings lists each setting with its value, where the value comes from
+// and what the setting controls, followed by the files of the profile in paths
+func (d *Display) ShowSettings(settings *storage.Settings, paths storage.Paths) {
+	keys := storage.SettingKeys()
+	width := 0
+	for _, key := range keys {
+		width = max(width, len(key))
+	}
+	for _, key := range keys {
+		value, _ := settings.Get(key)
+		usage, _ := storage.SettingUsage(key)
+		fmt.Printf("%-*s  %-8s ", width, key, value)
+		d.colorManager.PrintfDim("%-18s %s", "("+settings.Source(key)+")", usage)
+		fmt.Println()
+	}
+	fmt.Println()
+	if paths.Profile != "" {
+		d.colorManager.PrintfDim("Profile:       %s", paths.Profile)
+		fmt.Println()
+	}
+	d.colorManager.PrintfDim("Settings file: %s", storage.SettingsPath(paths.ConfigDir))
+	fmt.Println()
+	d.colorManager.PrintfDim("Habits file:   %s", filepath.Join(paths.ConfigDir, "habits"))
+	fmt.Println()
+	d.colorManager.PrintfDim("Log file:      %s", filepath.Join(paths.LogDir, "log"))
+	fmt.Println()
+}
+
 // ShowHabitLog displays the habit log with sparkline and graphs
 // If hideEnded is true, habits with an end date are not displayed
 func (d *Display) ShowHabitLog(habits []*storage.Habit, entries *storage.Entries, countBack int, maxHabitNameLength int, habitFragment string, hideEnded bool) {
@@ -81,8 +309,8 @@ func (d *Display) ShowHabitLog(habits []*storage.Habit, entries *storage.Entries
 			d.colorManager.PrintfBold("%s\n", habit.Heading)
 			heading = habit.Heading
 		}
-		// Mute the habit name and graph if the habit has ended
-		if habit.IsEnded() {
+		// Mute the habit name and graph if the habit has ended or not yet started
+		if habit.IsEnded() || !habit.HasStarted(now) {
 			d.colorManager.PrintfMuted("%*v", maxHabitNameLength, habit.Name+"  ")
 			d.colorManager.PrintMuted(graphResults[habit.Name])
 		} else {
@@ -172,10 +400,47 @@ func (d *Display) ShowHabitStats(habits []*storage.Habit, entries *storage.Entri
 	}
 }
 
-// ShowTodos displays undone habits for today and recent days
-func (d *Display) ShowTodos(habits []*storage.Habit, entries *storage.Entries, maxHabitNameLength int) {
+// ShowTimeStats displays the time of day each habit is usually done and
+// whether that time has been drifting. Habits with too few timed entries
+// to say are listed as such.
+func (d *Display) ShowTimeStats(habits []*storage.Habit, entries *storage.Entries, maxHabitNameLength int, hideEnded bool) {
+	heading := ""
+	for _, habit := range habits {
+		if hideEnded && habit.IsEnded() {
+			continue
+		}
+		if heading != habit.Heading {
+			d.colorManager.PrintfBold("\n%s\n", habit.Heading)
+			heading = habit.Heading
+		}
+		if habit.IsEnded() {
+			d.colorManager.PrintfMuted("%*v", maxHabitNameLength, habit.Name+"  ")
+		} else {
+			fmt.Printf("%*v", maxHabitNameLength, habit.Name+"  ")
+		}
+
+		stats := BuildTimeStats(habit, entries)
+		if stats.Count < minTimedEntries {
+			d.colorManager.PrintfDim("not enough timed entries (%d)\n", stats.Count)
+			continue
+		}
+		fmt.Printf("usually done at %s", stats.Usual)
+		switch {
+		case stats.Drift >= driftThreshold:
+			d.colorManager.PrintfYellow(", drifting later (%s recently, +%dm)", stats.Recent, stats.Drift)
+		case stats.Drift <= -driftThreshold:
+			d.colorManager.PrintfYellow(", drifting earlier (%s recently, %dm)", stats.Recent, stats.Drift)
+		case stats.Recent != "":
+			d.colorManager.PrintfGreen(", steady")
+		}
+		d.colorManager.PrintfDim("  (%d times)\n", stats.Count)
+	}
+}
+
+// ShowTodos displays undone habits for today and the daysBack days before it
+func (d *Display) ShowTodos(habits []*storage.Habit, entries *storage.Entries, maxHabitNameLength int, daysBack int) {
 	now := civil.DateOf(time.Now())
-	undone := GetTodos(habits, entries, now, 8)
+	undone := GetTodos(habits, entries, now, daysBack)
 
 	heading := ""
 	if len(undone) == 0 {
@@ -212,8 +477,8 @@ func (d *Display) ShowTodos(habits []*storage.Habit, entries *storage.Entries, m
 
 						// Format the due string
 						var dueStr string
-						if daysUntil == -1 && habit.Target < 1 {
-							// Tracking-only habit - no streak indicator
+						if daysUntil == -1 && (habit.Target < 1 || habit.Avoid) {
+							// Tracking-only or avoidance habit - no streak indicator
 							dueStr = ""
 						} else if daysUntil == -1 {
 							// Habit not yet started but has a target - show as broken
@@ -284,8 +549,13 @@ func GetTodos(habits []*storage.Habit, entries *storage.Entries, to civil.Date,
 				if dt.Before(habit.FirstRecord) {
 					delete(dayHabits, habit.Name)
 				}
-				// Remove habits that have ended (after EndRecord)
-				if habit.HasEnded(dt) {
+				// Remove habits that have not started (before StartRecord),
+				// have ended (after EndRecord), are paused or are not due
+				if !habit.HasStarted(dt) || habit.HasEnded(dt) || habit.IsPaused(dt) || !habit.IsScheduled(dt) {
+					delete(dayHabits, habit.Name)
+				}
+				// Habits to avoid need no check-in, as a day not logged is kept
+				if habit.Avoid {
 					delete(dayHabits, habit.Name)
 				}
 			}
@@ -315,8 +585,27 @@ func BuildStats(habit *storage.Habit, entries *storage.Entries) HabitStats {
 	}
 
 	for d := habit.FirstRecord; !d.After(to); d = d.AddDays(1) {
+		if habit.Avoid {
+			outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]
+			switch {
+			case ok && outcome.Result == "s":
+				skips += 1
+			case graph.OverLimit(d, habit, *entries):
+				breaks += 1
+			default:
+				streaks += 1
+			}
+			total += outcome.Amount
+			continue
+		}
 		if outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]; ok {
 			switch {
+			case habit.Amount > 0 && outcome.Result == "y":
+				if graph.SatisfiedByCompletions(d, habit, *entries) {
+					streaks += 1
+				} else {
+					breaks += 1
+				}
 			case outcome.Result == "y":
 				streaks += 1
 			case outcome.Result == "s":
@@ -332,5 +621,88 @@ func BuildStats(habit *storage.Habit, entries *storage.Entries) HabitStats {
 			total += outcome.Amount
 		}
 	}
-	return HabitStats{DaysTracked: int((to.DaysSince(habit.FirstRecord)) + 1), Streaks: streaks, Breaks: breaks, Skips: skips, Total: total}
+	return HabitStats{DaysTracked: max(0, to.DaysSince(habit.FirstRecord)+1), Streaks: streaks, Breaks: breaks, Skips: skips, Total: total}
 }
+
+const (
+	minTimedEntries = 3  // Timed entries needed before a usual time is shown
+	recentEntries   = 7  // Latest timed entries compared against the rest for drift
+	driftThreshold  = 15 // Minutes of difference reported as drifting
+)
+
+// TimeStats holds the time of day a habit is usually done
+type TimeStats struct {
+	Count  int    // Entries done with a recorded time
+	Usual  string // Average time of day over all of them, HH:MM
+	Recent string // Average time of the latest entries, empty if too few to compare
+	Drift  int    // Minutes the recent average is later (or, negative, earlier) than before
+}
+
+// BuildTimeStats averages the recorded times a habit was done. Times are
+// averaged around the clock so 23:50 and 00:10 average to midnight, which
+// keeps bedtime habits meaningful.
+func BuildTimeStats(habit *storage.Habit, entries *storage.Entries) TimeStats {
+	var minutes []int
+	if habit.FirstRecord.IsZero() {
+		return TimeStats{}
+	}
+	now := civil.DateOf(time.Now())
+	for d := habit.FirstRecord; !d.After(now); d = d.AddDays(1) {
+		outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]
+		if !ok || outcome.Result != "y" || outcome.Time == "" {
+			continue
+		}
+		t, err := time.Parse(storage.TimeLayout, outcome.Time)
+		if err != nil {
+			continue
+		}
+		minutes = append(minutes, t.Hour()*60+t.Minute())
+	}
+
+	stats := TimeStats{Count: len(minutes)}
+	if stats.Count == 0 {
+		return stats
+	}
+	usual := meanTimeOfDay(minutes)
+	stats.Usual = formatTimeOfDay(usual)
+
+	// Compare the latest entries against the ones before them
+	recent := min(recentEntries, len(minutes)/2)
+	if recent >= minTimedEntries {
+		split := len(minutes) - recent
+		latest := meanTimeOfDay(minutes[split:])
+		stats.Recent = formatTimeOfDay(latest)
+		stats.Drift = clockDifference(latest, meanTimeOfDay(minutes[:split]))
+	}
+	return stats
+}
+
+// meanTimeOfDay averages minutes past midnight on a 24 hour circle
+func meanTimeOfDay(minutes []int) int {
+	var x, y float64
+	for _, m := range minutes {
+		angle := float64(m) / minutesPerDay * 2 * math.Pi
+		x += math.Cos(angle)
+		y += math.Sin(angle)
+	}
+	angle := math.Atan2(y, x)
+	mean := int(math.Round(angle / (2 * math.Pi) * minutesPerDay))
+	return ((mean % minutesPerDay) + minutesPerDay) % minutesPerDay
+}
+
+// clockDifference returns the shortest signed difference a - b in minutes
+func clockDifference(a int, b int) int {
+	diff := (a - b) % minutesPerDay
+	if diff > minutesPerDay/2 {
+		diff -= minutesPerDay
+	} else if diff < -minutesPerDay/2 {
+		diff += minutesPerDay
+	}
+	return diff
+}
+
+func formatTimeOfDay(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+const minutesPerDay = 24 * 60
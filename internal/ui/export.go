@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/wakatara/harsh/internal/storage"
+)
+
+// exportColumns is the header row of a CSV or TSV export
+var exportColumns = []string{"date", "habit", "heading", "result", "status", "amount", "comment", "time"}
+
+// ExportTable writes the daily history of habits between two days as a flat
+// table, one row per habit and day, with comma or tab as the separator. Each
+// row carries the derived status the graph shows for the day (done, skip,
+// satisfied, skipified, break, warning or unrecorded), so days with nothing
+// logged are included too. Days before a habit's first entry and after its
+// end date are left out. A zero from starts at the earliest first entry.
+func ExportTable(w io.Writer, separator rune, habits []*storage.Habit, entries *storage.Entries, from civil.Date, to civil.Date, habitFragment string, hideEnded bool) error {
+	habits = filterHabits(habits, habitFragment, hideEnded)
+	if from.IsZero() {
+		for _, habit := range habits {
+			if !habit.FirstRecord.IsZero() && (from.IsZero() || habit.FirstRecord.Before(from)) {
+				from = habit.FirstRecord
+			}
+		}
+	}
+
+	out := csv.NewWriter(w)
+	out.Comma = separator
+	if err := out.Write(exportColumns); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	now := civil.DateOf(time.Now())
+	if !from.IsZero() {
+		for d := from; !d.After(to); d = d.AddDays(1) {
+			for _, habit := range habits {
+				if habit.FirstRecord.IsZero() || d.Before(habit.FirstRecord) || habit.HasEnded(d) {
+					continue
+				}
+				outcome := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]
+				row := []string{
+					d.String(),
+					habit.Name,
+					habit.Heading,
+					outcome.Result,
+					entryStatus(d, now, habit, entries),
+					outcome.AmountString(),
+					outcome.Comment,
+					outcome.Time,
+				}
+				if err := out.Write(row); err != nil {
+					return fmt.Errorf("failed to write export: %w", err)
+				}
+			}
+		}
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// filterHabits returns the habits whose names contain habitFragment, ignoring
+// case, leaving out habits with an end date when hideEnded is set
+func filterHabits(habits []*storage.Habit, habitFragment string, hideEnded bool) []*storage.Habit {
+	fragment := strings.ToLower(strings.TrimSpace(habitFragment))
+	filtered := []*storage.Habit{}
+	for _, habit := range habits {
+		if fragment != "" && !strings.Contains(strings.ToLower(habit.Name), fragment) {
+			continue
+		}
+		if hideEnded && habit.IsEnded() {
+			continue
+		}
+		filtered = append(filtered, habit)
+	}
+	return filtered
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/wakatara/harsh/internal/graph"
+	"github.com/wakatara/harsh/internal/storage"
+)
+
+// icsLineLength is the longest content line iCalendar allows, in octets
+const icsLineLength = 75
+
+// ExportICS writes an iCalendar feed with one all-day item per habit on the
+// date it must next be done to keep its streak, as events or, with todo set,
+// as to-dos. Broken and unstarted streaks are due today. Tracking-only and
+// ended habits are left out. alarm is the HH:MM time on the due date to be
+// reminded at, or empty for no reminders. Items keep the same UID from run
+// to run, so a calendar subscribed to a regenerated file moves them rather
+// than adding new ones.
+func ExportICS(w io.Writer, habits []*storage.Habit, entries *storage.Entries, habitFragment string, hideEnded bool, todo bool, alarm string) error {
+	now := time.Now()
+	today := civil.DateOf(now)
+
+	var trigger string
+	if alarm != "" {
+		t, err := time.Parse(storage.TimeLayout, alarm)
+		if err != nil {
+			return fmt.Errorf("invalid alarm time %q (expected HH:MM)", alarm)
+		}
+		trigger = fmt.Sprintf("PT%dH%dM", t.Hour(), t.Minute())
+	}
+
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		b.WriteString(foldICSLine(fmt.Sprintf(format, args...)))
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//harsh//harsh habit tracker//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:harsh")
+
+	component := "VEVENT"
+	if todo {
+		component = "VTODO"
+	}
+	for _, habit := range filterHabits(habits, habitFragment, hideEnded) {
+		if habit.Target < 1 || habit.Avoid || habit.HasEnded(today) {
+			continue
+		}
+
+		due, intact := nextDueDate(today, habit, entries)
+		description := fmt.Sprintf("Do %s by %s to keep your streak (%s) going.", habit.Name, due, habit.Frequency)
+		if habit.FirstRecord.IsZero() {
+			description = fmt.Sprintf("Do %s today to start a streak.", habit.Name)
+		} else if !intact {
+			description = fmt.Sprintf("Your %s streak is broken, do it today to start a new one.", habit.Name)
+		}
+		summary := fmt.Sprintf("%s (%s)", habit.Name, habit.Frequency)
+		sum := sha1.Sum([]byte(habit.Name))
+
+		line("BEGIN:%s", component)
+		line("UID:harsh-%s@harsh", hex.EncodeToString(sum[:8]))
+		line("DTSTAMP:%s", now.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:%s", icsDate(due))
+		if todo {
+			line("DUE;VALUE=DATE:%s", icsDate(due.AddDays(1)))
+			line("STATUS:NEEDS-ACTION")
+		} else {
+			line("DTEND;VALUE=DATE:%s", icsDate(due.AddDays(1)))
+			line("TRANSP:TRANSPARENT")
+		}
+		line("SUMMARY:%s", escapeICSText(summary))
+		line("DESCRIPTION:%s", escapeICSText(description))
+		if habit.Heading != "" {
+			line("CATEGORIES:%s", escapeICSText(habit.Heading))
+		}
+		if trigger != "" {
+			line("BEGIN:VALARM")
+			line("ACTION:DISPLAY")
+			line("DESCRIPTION:%s", escapeICSText(summary))
+			line("TRIGGER;RELATED=START:%s", trigger)
+			line("END:VALARM")
+		}
+		line("END:%s", component)
+	}
+	line("END:VCALENDAR")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	return nil
+}
+
+// nextDueDate returns the date habit must next be done to keep its streak
+// and whether the streak is intact. Broken and unstarted streaks are due today,
+// and habits with a start date yet to come on that date.
+func nextDueDate(today civil.Date, habit *storage.Habit, entries *storage.Entries) (civil.Date, bool) {
+	if !habit.HasStarted(today) {
+		return habit.StartRecord, false
+	}
+	if habit.FirstRecord.IsZero() {
+		return today, false
+	}
+	daysUntil := graph.DaysUntilStreakBreak(today, habit, *entries)
+	if daysUntil < 0 {
+		return today, false
+	}
+	return today.AddDays(daysUntil), true
+}
+
+// icsDate formats a date as an iCalendar DATE value
+func icsDate(d civil.Date) string {
+	return fmt.Sprintf("%04d%02d%02d", d.Year, d.Month, d.Day)
+}
+
+// escapeICSText escapes a TEXT value for an iCalendar content line
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldICSLine ends a content line with CRLF, folding it onto continuation
+// lines starting with a space where it is longer than iCalendar allows.
+// Lines are only folded between characters, never inside one.
+func foldICSLine(s string) string {
+	var b strings.Builder
+	limit := icsLineLength
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		// The leading space counts towards the continuation line's length
+		limit = icsLineLength - 1
+	}
+	b.WriteString(s + "\r\n")
+	return b.String()
+}
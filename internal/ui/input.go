@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -53,6 +54,99 @@ func (i *Input) Onboard() int {
 	return numberOfDays
 }
 
+// AskEntry prompts for a replacement result for an existing log entry.
+// Returns ok false if the user leaves the entry unchanged with ⏎.
+func (i *Input) AskEntry(d civil.Date, habit string, outcome storage.Outcome, maxHabitNameLength int) (result string, amount string, comment string, ok bool) {
+	i.colorManager.PrintlnBold(d.String() + " " + habit + ":")
+	fmt.Printf("%*v", maxHabitNameLength, "Currently  ")
+	fmt.Print(outcome.Result)
+	if outcome.Amount != 0 {
+		fmt.Printf(" @ %s", outcome.AmountString())
+	}
+	if outcome.Comment != "" {
+		fmt.Printf(" # %s", outcome.Comment)
+	}
+	fmt.Printf("\n")
+
+	for {
+		fmt.Printf("%*v", maxHabitNameLength, "New  ")
+		fmt.Printf("[y/n/s/⏎] ")
+
+		reader := bufio.NewReader(os.Stdin)
+		habitResultInput, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		// No input
+		if len(strings.TrimSpace(habitResultInput)) == 0 {
+			return "", "", "", false
+		}
+
+		result, amount, comment := parseResultInput(habitResultInput)
+		if strings.ContainsAny(result, "yns") && len(result) == 1 {
+			return result, amount, comment, true
+		}
+
+		i.colorManager.PrintfRed("%*v", maxHabitNameLength+22, "Sorry! Please choose from")
+		i.colorManager.PrintfRed(" [y/n/s/⏎] " + "(+ optional @ amounts then # comments)" + "\n")
+	}
+}
+
+// ChooseConflict asks which of two differing outcomes for the same habit and
+// day to keep when merging a sync conflicted copy of the log
+func (i *Input) ChooseConflict(c storage.MergeConflict) storage.Outcome {
+	describe := func(o storage.Outcome) string {
+		text := o.Result
+		if o.Amount != 0 {
+			text += " @ " + o.AmountString()
+		}
+		if o.Comment != "" {
+			text += " # " + o.Comment
+		}
+		return text
+	}
+
+	i.colorManager.PrintlnBold(c.Key.Day.String() + " " + c.Key.Habit + ":")
+	fmt.Printf("  [1] %s  (%s)\n", describe(c.Current), filepath.Base(c.CurrentSource))
+	fmt.Printf("  [2] %s  (%s)\n", describe(c.Incoming), filepath.Base(c.Source))
+	for {
+		fmt.Printf("  Keep [1/2] ")
+
+		reader := bufio.NewReader(os.Stdin)
+		choice, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			// Without input there is nothing to decide with, keep what the log has
+			return c.Current
+		}
+
+		switch strings.TrimSpace(choice) {
+		case "1":
+			return c.Current
+		case "2":
+			return c.Incoming
+		}
+		i.colorManager.PrintfRed("  Sorry! Please choose 1 or 2\n")
+	}
+}
+
+// parseResultInput splits prompt input of the form "y @ amount # comment"
+// into its result, amount and comment parts. Everything after the first #
+// is the comment, so comments may contain @, colons and URLs.
+func parseResultInput(habitResultInput string) (result string, amount string, comment string) {
+	if hashIndex := strings.Index(habitResultInput, "#"); hashIndex >= 0 {
+		comment = strings.TrimSpace(habitResultInput[hashIndex+1:])
+		habitResultInput = habitResultInput[:hashIndex]
+	}
+	if atIndex := strings.Index(habitResultInput, "@"); atIndex >= 0 {
+		amount = strings.TrimSpace(habitResultInput[atIndex+1:])
+		habitResultInput = habitResultInput[:atIndex]
+	}
+	result = strings.TrimSpace(habitResultInput)
+	return result, amount, comment
+}
+
 // AskHabits handles the interactive habit asking process
 func (i *Input) AskHabits(habits []*storage.Habit, entries *storage.Entries, repository storage.Repository, maxHabitNameLength int, countBack int, check string) {
 	now := civil.DateOf(time.Now())
@@ -123,7 +217,14 @@ func (i *Input) AskHabits(habits []*storage.Habit, entries *storage.Entries, rep
 							for {
 								fmt.Printf("%*v", maxHabitNameLength, habit.Name+"  ")
 								fmt.Print(graph.BuildGraph(habit, entries, countBack, true))
-								fmt.Printf(" [y/n/s/⏎] ")
+								if habit.Description != "" {
+									i.colorManager.PrintfDim(" %s", habit.Description)
+								}
+								if habit.Unit != "" {
+									fmt.Printf(" [y/n/s/⏎ @ %s] ", habit.Unit)
+								} else {
+									fmt.Printf(" [y/n/s/⏎] ")
+								}
 
 								reader := bufio.NewReader(os.Stdin)
 								habitResultInput, err := reader.ReadString('\n')
@@ -136,43 +237,18 @@ func (i *Input) AskHabits(habits []*storage.Habit, entries *storage.Entries, rep
 									break
 								}
 
-								// Sanitize : colons out of string for log files
-								habitResultInput = strings.ReplaceAll(habitResultInput, ":", "")
-
-								var result, amount, comment string
-								atIndex := strings.Index(habitResultInput, "@")
-								hashIndex := strings.Index(habitResultInput, "#")
-
-								if atIndex > 0 && hashIndex > 0 && atIndex < hashIndex {
-									parts := strings.SplitN(habitResultInput, "@", 2)
-									secondParts := strings.SplitN(parts[1], "#", 2)
-									result = strings.TrimSpace(parts[0])
-									amount = strings.TrimSpace(secondParts[0])
-									comment = strings.TrimSpace(secondParts[1])
-								}
-								// only has an @ Amount
-								if hashIndex == -1 && atIndex > 0 {
-									parts := strings.SplitN(habitResultInput, "@", 2)
-									result = strings.TrimSpace(parts[0])
-									amount = strings.TrimSpace(parts[1])
-									comment = ""
-								}
-								// only has a # comment
-								if atIndex == -1 && hashIndex > 0 {
-									parts := strings.SplitN(habitResultInput, "#", 2)
-									result = strings.TrimSpace(parts[0])
-									amount = ""
-									comment = strings.TrimSpace(parts[1])
-								}
-								if atIndex == -1 && hashIndex == -1 {
-									result = strings.TrimSpace(habitResultInput)
-								}
+								result, amount, comment := parseResultInput(habitResultInput)
 
 								if strings.ContainsAny(result, "yns") && len(result) == 1 {
-									repository.WriteEntry(dt, habit.Name, result, comment, amount)
+									// Answers for today are stamped with the time they were given
+									timeOfDay := ""
+									if dt == now {
+										timeOfDay = time.Now().Format(storage.TimeLayout)
+									}
+									repository.WriteEntry(dt, habit.Name, result, comment, amount, timeOfDay)
 									// Updates the Entries map to get updated buildGraph across days
 									famount, _ := strconv.ParseFloat(amount, 64)
-									(*entries)[storage.DailyHabit{Day: dt, Habit: habit.Name}] = storage.Outcome{Result: result, Amount: famount, Comment: comment}
+									(*entries)[storage.DailyHabit{Day: dt, Habit: habit.Name}] = storage.Outcome{Result: result, Amount: famount, Comment: comment, Time: timeOfDay}
 									break
 								}
 
@@ -3,6 +3,9 @@ package ui
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,9 +28,20 @@ type scoresJSON struct {
 type habitJSON struct {
 	Name              string      `json:"name"`
 	Heading           string      `json:"heading"`
+	Aliases           []string    `json:"aliases,omitempty"`
 	Frequency         string      `json:"frequency"`
 	Target            int         `json:"target"`
 	Interval          int         `json:"interval"`
+	Period            string      `json:"period,omitempty"`
+	Avoid             bool        `json:"avoid,omitempty"`
+	Limit             *int        `json:"limit,omitempty"`
+	Paused            bool        `json:"paused,omitempty"`
+	Pauses            []string    `json:"pauses,omitempty"`
+	StartDate         *string     `json:"start_date,omitempty"`
+	EndDate           *string     `json:"end_date,omitempty"`
+	Unit              string      `json:"unit,omitempty"`
+	Tags              []string    `json:"tags,omitempty"`
+	Description       string      `json:"description,omitempty"`
 	LoggedToday       bool        `json:"logged_today"`
 	Result            *string     `json:"result"`
 	StreakStatus       string     `json:"streak_status"`
@@ -46,6 +60,7 @@ type entryJSON struct {
 	Status  string   `json:"status"`
 	Amount  *float64 `json:"amount,omitempty"`
 	Comment *string  `json:"comment,omitempty"`
+	Time    *string  `json:"time,omitempty"`
 }
 
 type statsJSON struct {
@@ -58,6 +73,13 @@ type statsJSON struct {
 
 // ShowHabitLogJSON outputs habit status as JSON for programmatic consumption
 func ShowHabitLogJSON(habits []*storage.Habit, entries *storage.Entries, habitFragment string, hideEnded bool) error {
+	return WriteHabitLogJSON(os.Stdout, habits, entries, habitFragment, hideEnded, false)
+}
+
+// WriteHabitLogJSON writes the JSON document of ShowHabitLogJSON to w. Each
+// habit's entries cover the last 100 days or, with fullHistory set, every
+// day since its first record, which ReadHabitLogJSON can read back.
+func WriteHabitLogJSON(w io.Writer, habits []*storage.Habit, entries *storage.Entries, habitFragment string, hideEnded bool, fullHistory bool) error {
 	now := civil.DateOf(time.Now())
 
 	// Filter habits by fragment if provided
@@ -85,11 +107,32 @@ func ShowHabitLogJSON(habits []*storage.Habit, entries *storage.Entries, habitFr
 	habitItems := make([]habitJSON, 0, len(filteredHabits))
 	for _, habit := range filteredHabits {
 		item := habitJSON{
-			Name:      habit.Name,
-			Heading:   habit.Heading,
-			Frequency: habit.Frequency,
-			Target:    habit.Target,
-			Interval:  habit.Interval,
+			Name:        habit.Name,
+			Heading:     habit.Heading,
+			Aliases:     habit.Aliases,
+			Frequency:   habit.Frequency,
+			Target:      habit.Target,
+			Interval:    habit.Interval,
+			Period:      habit.Period,
+			Unit:        habit.Unit,
+			Tags:        habit.Tags,
+			Description: habit.Description,
+		}
+		item.Paused = habit.IsPaused(now)
+		for _, pause := range append(slices.Clone(habit.Pauses), habit.GroupPauses...) {
+			item.Pauses = append(item.Pauses, pause.String())
+		}
+		if habit.Avoid {
+			item.Avoid = true
+			item.Limit = &habit.Limit
+		}
+		if !habit.StartRecord.IsZero() {
+			start := habit.StartRecord.String()
+			item.StartDate = &start
+		}
+		if !habit.EndRecord.IsZero() {
+			end := habit.EndRecord.String()
+			item.EndDate = &end
 		}
 
 		// Check if logged today
@@ -107,8 +150,10 @@ func ShowHabitLogJSON(habits []*storage.Habit, entries *storage.Entries, habitFr
 		switch {
 		case habit.Target < 1:
 			item.StreakStatus = "tracking"
-		case habit.FirstRecord == noFirstRecord:
+		case habit.FirstRecord == noFirstRecord || !habit.HasStarted(now):
 			item.StreakStatus = "unstarted"
+		case habit.Avoid && daysUntil == -1:
+			item.StreakStatus = "active"
 		case inSkipPeriod:
 			item.StreakStatus = "skipping"
 			item.DaysUntilBreak = &daysUntil
@@ -136,8 +181,15 @@ func ShowHabitLogJSON(habits []*storage.Habit, entries *storage.Entries, habitFr
 			item.CompletedInWindow = &count
 		}
 
-		// Daily entries for last 100 days (mirrors BuildGraph logic)
-		item.Entries = buildEntries(now, habit, entries, 100)
+		// Daily entries for the last 100 days, or the whole history (mirrors BuildGraph logic)
+		countBack := 100
+		if fullHistory {
+			countBack = -1
+			if !habit.FirstRecord.IsZero() {
+				countBack = now.DaysSince(habit.FirstRecord)
+			}
+		}
+		item.Entries = buildEntries(now, habit, entries, countBack)
 
 		// Stats
 		stats := BuildStats(habit, entries)
@@ -165,21 +217,103 @@ func ShowHabitLogJSON(habits []*storage.Habit, entries *storage.Entries, habitFr
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
-	fmt.Println(string(data))
+	if _, err := fmt.Fprintln(w, string(data)); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
 	return nil
 }
 
+// ReadHabitLogJSON reads habits and entries back from the JSON document
+// written by --json or harsh export json. Only the name, heading, aliases,
+// frequency, start and end dates, pauses, unit, tags and description of
+// habits and the date, result, amount, comment and time of entries are read;
+// derived fields such as status and streaks are ignored, as are days without
+// a result.
+func ReadHabitLogJSON(r io.Reader) ([]*storage.ImportedHabit, error) {
+	var doc logJSON
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid harsh JSON: %w", err)
+	}
+	if doc.Habits == nil {
+		return nil, fmt.Errorf("invalid harsh JSON: no habits")
+	}
+
+	imported := make([]*storage.ImportedHabit, 0, len(doc.Habits))
+	for i, item := range doc.Habits {
+		if strings.TrimSpace(item.Name) == "" || strings.TrimSpace(item.Frequency) == "" {
+			return nil, fmt.Errorf("invalid harsh JSON: habit %d needs a name and frequency", i+1)
+		}
+		ih := &storage.ImportedHabit{
+			Habit: storage.Habit{
+				Name: item.Name, Heading: item.Heading, Aliases: item.Aliases, Frequency: item.Frequency,
+				Unit: item.Unit, Tags: item.Tags, Description: item.Description,
+			},
+			Entries: map[civil.Date]storage.Outcome{},
+		}
+		if item.StartDate != nil {
+			start, err := civil.ParseDate(*item.StartDate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid harsh JSON: habit %q has an invalid start_date %q", item.Name, *item.StartDate)
+			}
+			ih.Habit.StartRecord = start
+		}
+		if item.EndDate != nil {
+			end, err := civil.ParseDate(*item.EndDate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid harsh JSON: habit %q has an invalid end_date %q", item.Name, *item.EndDate)
+			}
+			ih.Habit.EndRecord = end
+		}
+		for _, p := range item.Pauses {
+			pause, err := storage.ParsePause(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid harsh JSON: habit %q has an invalid pause %q", item.Name, p)
+			}
+			ih.Habit.Pauses = append(ih.Habit.Pauses, pause)
+		}
+
+		for _, entry := range item.Entries {
+			if entry.Result == nil {
+				continue
+			}
+			d, err := civil.ParseDate(entry.Date)
+			if err != nil {
+				return nil, fmt.Errorf("invalid harsh JSON: habit %q has an entry with invalid date %q", item.Name, entry.Date)
+			}
+			result := *entry.Result
+			if result != "y" && result != "n" && result != "s" {
+				return nil, fmt.Errorf("invalid harsh JSON: habit %q has an invalid result %q on %s", item.Name, result, d)
+			}
+			outcome := storage.Outcome{Result: result}
+			if entry.Amount != nil {
+				outcome.Amount = *entry.Amount
+			}
+			if entry.Comment != nil {
+				outcome.Comment = *entry.Comment
+			}
+			if entry.Time != nil {
+				t, err := storage.ParseTimeOfDay(*entry.Time)
+				if err != nil {
+					return nil, fmt.Errorf("invalid harsh JSON: habit %q on %s: %w", item.Name, d, err)
+				}
+				outcome.Time = t
+			}
+			ih.Entries[d] = outcome
+		}
+		imported = append(imported, ih)
+	}
+	return imported, nil
+}
+
 // buildEntries creates the daily entry history for a habit, mirroring BuildGraph() logic
 func buildEntries(to civil.Date, habit *storage.Habit, entries *storage.Entries, countBack int) []entryJSON {
 	from := to.AddDays(-countBack)
 	result := make([]entryJSON, 0, countBack+1)
 
 	for d := from; !d.After(to); d = d.AddDays(1) {
-		entry := entryJSON{Date: d.String()}
+		entry := entryJSON{Date: d.String(), Status: entryStatus(d, to, habit, entries)}
 
-		if habit.HasEnded(d) {
-			entry.Status = "ended"
-		} else if outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]; ok {
+		if outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]; ok && !habit.HasEnded(d) {
 			entry.Result = &outcome.Result
 			if outcome.Amount != 0 {
 				entry.Amount = &outcome.Amount
@@ -187,26 +321,8 @@ func buildEntries(to civil.Date, habit *storage.Habit, entries *storage.Entries,
 			if outcome.Comment != "" {
 				entry.Comment = &outcome.Comment
 			}
-
-			switch {
-			case outcome.Result == "y":
-				entry.Status = "done"
-			case outcome.Result == "s":
-				entry.Status = "skip"
-			case graph.SatisfiedByCompletions(d, habit, *entries) && !graph.IsInSkipPeriod(d, habit, *entries):
-				entry.Status = "satisfied"
-			case graph.Skipified(d, habit, *entries):
-				entry.Status = "skipified"
-			case outcome.Result == "n":
-				entry.Status = "break"
-			}
-		} else {
-			if graph.Warning(d, habit, *entries) && (to.DaysSince(d) < 14) {
-				entry.Status = "warning"
-			} else if d.After(habit.FirstRecord) {
-				entry.Status = "unrecorded"
-			} else {
-				entry.Status = "inactive"
+			if outcome.Time != "" {
+				entry.Time = &outcome.Time
 			}
 		}
 
@@ -216,6 +332,71 @@ func buildEntries(to civil.Date, habit *storage.Habit, entries *storage.Entries,
 	return result
 }
 
+// entryStatus derives the status of a habit on day d as the graph shows it:
+// done, partial, skip, paused, satisfied, skipified, break, warning,
+// unrecorded, inactive or ended. Warnings are only given for the two weeks up
+// to now.
+func entryStatus(d civil.Date, now civil.Date, habit *storage.Habit, entries *storage.Entries) string {
+	if habit.HasEnded(d) {
+		return "ended"
+	}
+	outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]
+	if !ok && habit.IsPaused(d) && habit.HasStarted(d) {
+		return "paused"
+	}
+	if habit.Avoid {
+		return avoidStatus(d, now, habit, entries)
+	}
+	if !ok {
+		switch {
+		case graph.Warning(d, habit, *entries) && (now.DaysSince(d) < 14):
+			return "warning"
+		case d.After(habit.FirstRecord) && habit.IsScheduled(d):
+			return "unrecorded"
+		default:
+			return "inactive"
+		}
+	}
+
+	switch {
+	case habit.Amount > 0 && outcome.Result == "y":
+		if graph.SatisfiedByCompletions(d, habit, *entries) {
+			return "done"
+		}
+		return "partial"
+	case outcome.Result == "y":
+		return "done"
+	case outcome.Result == "s":
+		return "skip"
+	case graph.SatisfiedByCompletions(d, habit, *entries) && !graph.IsInSkipPeriod(d, habit, *entries):
+		return "satisfied"
+	case graph.Skipified(d, habit, *entries):
+		return "skipified"
+	case outcome.Result == "n":
+		return "break"
+	}
+	return ""
+}
+
+// avoidStatus derives the status of an avoidance habit on day d as the graph
+// shows it, where days it was not done over its limit are done
+func avoidStatus(d civil.Date, now civil.Date, habit *storage.Habit, entries *storage.Entries) string {
+	outcome, ok := (*entries)[storage.DailyHabit{Day: d, Habit: habit.Name}]
+	switch {
+	case habit.FirstRecord.IsZero() || d.Before(habit.FirstRecord):
+		return "inactive"
+	case ok && outcome.Result == "s":
+		return "skip"
+	case graph.OverLimit(d, habit, *entries):
+		return "break"
+	case ok && outcome.Result == "y":
+		return "satisfied"
+	case graph.Warning(d, habit, *entries) && now.DaysSince(d) < 14:
+		return "warning"
+	}
+	return "done"
+}
+
 // lastCompleted finds the most recent date a habit was completed (y or s)
 func lastCompleted(d civil.Date, habit *storage.Habit, entries *storage.Entries) civil.Date {
 	noDate := civil.Date{}
@@ -232,10 +413,14 @@ func lastCompleted(d civil.Date, habit *storage.Habit, entries *storage.Entries)
 	return noDate
 }
 
-// completedInWindow counts y/s results within the current interval window
+// completedInWindow counts y/s results within the current interval window,
+// or the calendar period so far for calendar period habits
 func completedInWindow(d civil.Date, habit *storage.Habit, entries *storage.Entries) int {
 	count := 0
 	from := d.AddDays(-habit.Interval + 1)
+	if habit.Period != "" {
+		from, _ = graph.PeriodBounds(d, habit)
+	}
 	for dt := from; !dt.After(d); dt = dt.AddDays(1) {
 		if v, ok := (*entries)[storage.DailyHabit{Day: dt, Habit: habit.Name}]; ok {
 			if v.Result == "y" || v.Result == "s" {
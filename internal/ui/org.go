@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/wakatara/harsh/internal/storage"
+)
+
+// orgStates maps log results to the logbook states of an org export
+var orgStates = map[string]string{"y": "DONE", "s": "SKIP", "n": "MISSED"}
+
+// ExportOrg writes habits as org-mode TODOs with STYLE habit, for org-agenda's
+// habit tracking. Each is scheduled on the date it must next be done, with a
+// repeater from its frequency: 3/7 becomes ".+2d/3d", done again within two
+// to three days. Entries go in the LOGBOOK drawer, newest first, as DONE,
+// SKIP or MISSED state changes with comments and amounts as notes. Headings
+// become parent headlines, and the exact frequency and start and end dates are
+// kept in HARSH_ properties so harsh import org reads the file back unchanged.
+// Habits with an end date are written as DONE, closed on that date.
+func ExportOrg(w io.Writer, habits []*storage.Habit, entries *storage.Entries, habitFragment string, hideEnded bool) error {
+	today := civil.DateOf(time.Now())
+	habits = filterHabits(habits, habitFragment, hideEnded)
+
+	byHabit := map[string][]storage.DailyHabit{}
+	keys := storage.SortedKeys(*entries)
+	for i := len(keys) - 1; i >= 0; i-- {
+		byHabit[keys[i].Habit] = append(byHabit[keys[i].Habit], keys[i])
+	}
+
+	var b strings.Builder
+	b.WriteString("#+TITLE: harsh habits\n#+STARTUP: logdrawer\n")
+	heading := ""
+	for _, habit := range habits {
+		stars := "*"
+		if habit.Heading != "" {
+			if habit.Heading != heading {
+				b.WriteString("\n* " + habit.Heading + "\n")
+				heading = habit.Heading
+			}
+			stars = "**"
+		}
+
+		if !habit.EndRecord.IsZero() {
+			fmt.Fprintf(&b, "%s DONE %s\nCLOSED: %s\n", stars, habit.Name, orgTimestamp(habit.EndRecord, "", '[', ""))
+		} else if habit.Target < 1 || habit.Avoid {
+			fmt.Fprintf(&b, "%s TODO %s\n", stars, habit.Name)
+		} else {
+			due, _ := nextDueDate(today, habit, entries)
+			fmt.Fprintf(&b, "%s TODO %s\nSCHEDULED: %s\n", stars, habit.Name, orgTimestamp(due, "", '<', orgRepeater(habit)))
+		}
+		b.WriteString(":PROPERTIES:\n")
+		if habit.Target >= 1 && !habit.Avoid {
+			b.WriteString(":STYLE:    habit\n")
+		}
+		b.WriteString(":HARSH_FREQUENCY: " + habit.Frequency + "\n")
+		if !habit.StartRecord.IsZero() {
+			b.WriteString(":HARSH_START: " + habit.StartRecord.String() + "\n")
+		}
+		if !habit.EndRecord.IsZero() {
+			b.WriteString(":HARSH_END: " + habit.EndRecord.String() + "\n")
+		}
+		b.WriteString(":END:\n")
+
+		if len(byHabit[habit.Name]) == 0 {
+			continue
+		}
+		b.WriteString(":LOGBOOK:\n")
+		for _, key := range byHabit[habit.Name] {
+			outcome := (*entries)[key]
+			state, ok := orgStates[outcome.Result]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&b, "- State %-12s from %-12s %s", `"`+state+`"`, `"TODO"`, orgTimestamp(key.Day, outcome.Time, '[', ""))
+			var note []string
+			if outcome.Comment != "" {
+				note = strings.Split(outcome.Comment, "\n")
+			}
+			if outcome.Amount != 0 {
+				note = append(note, "Amount: "+outcome.AmountString())
+			}
+			if len(note) > 0 {
+				b.WriteString(" \\\\\n  " + strings.Join(note, "\n  "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(":END:\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write org file: %w", err)
+	}
+	return nil
+}
+
+// orgTimestamp formats an org timestamp such as "<2025-01-05 Sun .+2d/3d>"
+// or "[2025-01-03 Fri 07:30]", with open as '<' for active or '[' for
+// inactive timestamps. timeOfDay and repeater are left out when empty.
+func orgTimestamp(d civil.Date, timeOfDay string, open byte, repeater string) string {
+	s := d.String() + " " + d.In(time.UTC).Weekday().String()[:3]
+	if timeOfDay != "" {
+		s += " " + timeOfDay
+	}
+	if repeater != "" {
+		s += " " + repeater
+	}
+	if open == '<' {
+		return "<" + s + ">"
+	}
+	return "[" + s + "]"
+}
+
+// orgRepeater converts a habit's frequency to an org-habit repeater. Org
+// habits give the least and most days between completions, so a target of
+// several times an interval is spread evenly across it.
+func orgRepeater(habit *storage.Habit) string {
+	if habit.Target <= 1 {
+		return fmt.Sprintf(".+%dd", habit.Interval)
+	}
+	least := max(1, habit.Interval/habit.Target)
+	most := (habit.Interval + habit.Target - 1) / habit.Target
+	if least >= most {
+		return fmt.Sprintf(".+%dd", least)
+	}
+	return fmt.Sprintf(".+%dd/%dd", least, most)
+}
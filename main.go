@@ -1,13 +1,14 @@
 package main
 
 import (
-	"log"
+	"os"
 
 	"github.com/wakatara/harsh/cmd"
 )
 
 func main() {
+	// cobra has already reported the error on stderr
 	if err := cmd.Execute(); err != nil {
-		log.Fatal(err)
+		os.Exit(1)
 	}
 }
\ No newline at end of file
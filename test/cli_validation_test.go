@@ -2,6 +2,7 @@ package test
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -107,7 +108,7 @@ Practice guitar: 3/7
 	}
 
 	// Load habits
-	habits, _ := storage.LoadHabitsConfig(tmpDir)
+	habits, _, _, _ := storage.LoadHabitsConfig(tmpDir)
 	if len(habits) == 0 {
 		t.Fatal("No habits loaded for fragment testing")
 	}
@@ -256,7 +257,7 @@ func TestEnvironmentVariableValidation(t *testing.T) {
 					
 					// Test that we can use this directory
 					storage.CreateExampleHabitsFile(testDir)
-					habits, _ := storage.LoadHabitsConfig(testDir)
+					habits, _, _, _ := storage.LoadHabitsConfig(testDir)
 					if len(habits) == 0 {
 						t.Errorf("Failed to use valid path '%s' (%s)", tt.path, tt.description)
 					}
@@ -500,4 +501,99 @@ func TestScriptingAndAutomationValidation(t *testing.T) {
 	}
 	
 	t.Log("These tests document validation needs for safe automation usage")
-}
\ No newline at end of file
+}
+// TestTagFlagOnlyFiltersDisplay verifies --tag narrows what is shown, while
+// commands that rewrite the habits file or log still see every habit
+func TestTagFlagOnlyFiltersDisplay(t *testing.T) {
+	buildCmd := exec.Command("go", "build", "-o", "harsh-test-tag", ".")
+	buildCmd.Dir = ".."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build test binary: %v", err)
+	}
+	defer func() {
+		cleanCmd := exec.Command("rm", "harsh-test-tag")
+		cleanCmd.Dir = ".."
+		_ = cleanCmd.Run()
+	}()
+
+	harshPath := t.TempDir()
+	habits := "! Health\nRun: 3/7 [tags=health]\n! Work\nStandup: 1\n"
+	log := "2025-01-10 : Run : y\n2025-01-10 : Standup : y\n2025-01-11 : Standup : y\n"
+	if err := os.WriteFile(filepath.Join(harshPath, "habits"), []byte(habits), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(harshPath, "log"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) string {
+		cmd := exec.Command("./harsh-test-tag", args...)
+		cmd.Dir = ".."
+		cmd.Env = append(os.Environ(), "HARSHPATH="+harshPath)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("harsh %v failed: %v\nOutput: %s", args, err, output)
+		}
+		return string(output)
+	}
+
+	if output := run("log", "--tag", "health"); !strings.Contains(output, "Run") || strings.Contains(output, "Standup") {
+		t.Errorf("log --tag health should only show Run, got:\n%s", output)
+	}
+
+	backup := filepath.Join(t.TempDir(), "backup.json")
+	run("export", "json", "--output", backup)
+	if output := run("import", "json", backup, "--tag", "health"); !strings.Contains(output, "0 habits added") {
+		t.Errorf("import --tag should find every habit already defined, got: %s", output)
+	}
+	run("log", "tidy", "--tag", "health", "--archive-orphans")
+
+	if got, _ := os.ReadFile(filepath.Join(harshPath, "habits")); string(got) != habits {
+		t.Errorf("Habits file changed to %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(harshPath, "log")); string(got) != log {
+		t.Errorf("Log changed to %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(harshPath, "log.orphaned")); err == nil {
+		t.Error("Untagged habits should not be archived as orphans")
+	}
+}
+
+func TestInvalidColorFlag(t *testing.T) {
+	buildCmd := exec.Command("go", "build", "-o", "harsh-test-color", ".")
+	buildCmd.Dir = ".."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build test binary: %v", err)
+	}
+	defer func() {
+		cleanCmd := exec.Command("rm", "harsh-test-color")
+		cleanCmd.Dir = ".."
+		_ = cleanCmd.Run()
+	}()
+
+	harshPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(harshPath, "habits"), []byte("Run: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(harshPath, "log"), []byte("2025-01-10 : Run : y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run := func(env string, args ...string) (string, error) {
+		cmd := exec.Command("./harsh-test-color", args...)
+		cmd.Dir = ".."
+		cmd.Env = append(os.Environ(), "HARSHPATH="+harshPath, env)
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+
+	output, err := run("HARSH_COLOR=bogus", "log")
+	if err == nil || !strings.Contains(output, `Error: HARSH_COLOR: invalid color "bogus"`) {
+		t.Errorf("Expected an invalid HARSH_COLOR to be an error, got %v: %s", err, output)
+	}
+	output, err = run("", "log", "--color", "bogus")
+	if err == nil || !strings.Contains(output, `Error: --color: invalid color "bogus"`) {
+		t.Errorf("Expected an invalid --color to be reported like HARSH_COLOR, got %v: %s", err, output)
+	}
+	if output, err := run("", "log", "--color", "Never"); err != nil || strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected --color Never to turn colors off, got %v: %q", err, output)
+	}
+}
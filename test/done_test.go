@@ -0,0 +1,83 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+// TestDoneCommand verifies non-interactive logging through 'harsh done'
+func TestDoneCommand(t *testing.T) {
+	buildCmd := exec.Command("go", "build", "-o", "harsh-test-done", ".")
+	buildCmd.Dir = ".."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build test binary: %v", err)
+	}
+	defer func() {
+		cleanCmd := exec.Command("rm", "harsh-test-done")
+		cleanCmd.Dir = ".."
+		_ = cleanCmd.Run()
+	}()
+
+	harshPath := t.TempDir()
+	habits := "Gym: 3/7\nGym class: 7\nCalled Mom: 1w\nRead: 1\n"
+	if err := os.WriteFile(filepath.Join(harshPath, "habits"), []byte(habits), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(harshPath, "log"), []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	today := civil.DateOf(time.Now())
+	yesterday := today.AddDays(-1)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		wantLine string
+	}{
+		{"Defaults to y today", []string{"done", "read", "--time", "7:05"}, false, today.String() + " : Read : y :  :  : 07:05\n"},
+		{"Exact name beats fragment", []string{"done", "Gym", "s", "--time", "21:30"}, false, today.String() + " : Gym : s :  :  : 21:30\n"},
+		{"Date, amount and comment", []string{"done", "mom", "n", "--date", "yday", "--amount", "2", "--comment", "next week"}, false, yesterday.String() + " : Called Mom : n : next week : 2\n"},
+		{"Ambiguous habit", []string{"done", "g"}, true, ""},
+		{"Unknown habit", []string{"done", "swim"}, true, ""},
+		{"Invalid result", []string{"done", "read", "x"}, true, ""},
+		{"Invalid amount", []string{"done", "read", "--amount", "lots"}, true, ""},
+		{"Invalid time", []string{"done", "read", "--time", "25:00"}, true, ""},
+		{"Future date", []string{"done", "read", "--date", today.AddDays(1).String()}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := os.ReadFile(filepath.Join(harshPath, "log"))
+
+			cmd := exec.Command("./harsh-test-done", tt.args...)
+			cmd.Dir = ".."
+			cmd.Env = append(os.Environ(), "HARSHPATH="+harshPath)
+			output, err := cmd.CombinedOutput()
+
+			after, _ := os.ReadFile(filepath.Join(harshPath, "log"))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected non-zero exit, got success: %s", output)
+				}
+				if len(after) != len(before) {
+					t.Errorf("Log should be unchanged on error, got %q", after)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+			}
+			if !strings.HasSuffix(string(after), tt.wantLine) {
+				t.Errorf("Expected log to end with %q, got %q", tt.wantLine, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEditCommand verifies that 'harsh edit' only changes the parts of an
+// entry given, on the command line or at the prompt, wherever it is logged
+func TestEditCommand(t *testing.T) {
+	buildCmd := exec.Command("go", "build", "-o", "harsh-test-edit", ".")
+	buildCmd.Dir = ".."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build test binary: %v", err)
+	}
+	defer func() {
+		cleanCmd := exec.Command("rm", "harsh-test-edit")
+		cleanCmd.Dir = ".."
+		_ = cleanCmd.Run()
+	}()
+
+	harshPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(harshPath, "habits"), []byte("Gym: 3/7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantLine string
+	}{
+		{"Prompted result only", []string{"edit", "gym", "2025-01-10"}, "n\n", "2025-01-10 : Gym : n : legs : 5 : 07:30\n"},
+		{"Prompted result and comment", []string{"edit", "gym", "2025-01-10"}, "s # sick\n", "2025-01-10 : Gym : s : sick : 5 : 07:30\n"},
+		{"Prompted result and amount", []string{"edit", "gym", "2025-01-10"}, "y @ 7\n", "2025-01-10 : Gym : y : legs : 7 : 07:30\n"},
+		{"Result argument only", []string{"edit", "gym", "2025-01-10", "n"}, "", "2025-01-10 : Gym : n : legs : 5 : 07:30\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logFile := filepath.Join(harshPath, "log")
+			if err := os.WriteFile(logFile, []byte("2025-01-10 : Gym : y : legs : 5 : 07:30\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := exec.Command("./harsh-test-edit", tt.args...)
+			cmd.Dir = ".."
+			cmd.Env = append(os.Environ(), "HARSHPATH="+harshPath)
+			cmd.Stdin = strings.NewReader(tt.stdin)
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+			}
+
+			if got, _ := os.ReadFile(logFile); string(got) != tt.wantLine {
+				t.Errorf("Expected the log %q, got %q", tt.wantLine, got)
+			}
+		})
+	}
+	// Entries older than the log are changed in their archive
+	logFile := filepath.Join(harshPath, "log")
+	if err := os.WriteFile(logFile, []byte("2025-01-10 : Gym : y :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	archiveFile := filepath.Join(harshPath, "log.2022")
+	if err := os.WriteFile(archiveFile, []byte("2022-03-01 : Gym : y : legs : 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("./harsh-test-edit", "edit", "gym", "2022-03-01", "s")
+	cmd.Dir = ".."
+	cmd.Env = append(os.Environ(), "HARSHPATH="+harshPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Editing an archived entry failed: %v\nOutput: %s", err, output)
+	}
+	if got, _ := os.ReadFile(archiveFile); string(got) != "2022-03-01 : Gym : s : legs : 5\n" {
+		t.Errorf("Expected the archived entry edited, got %q", got)
+	}
+	if got, _ := os.ReadFile(logFile); string(got) != "2025-01-10 : Gym : y :  : \n" {
+		t.Errorf("Expected the log unchanged, got %q", got)
+	}
+}
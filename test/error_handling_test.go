@@ -37,7 +37,7 @@ invalid-date : Test : y : Bad date : 1.0
 		}
 
 		// LoadLog should now handle malformed entries gracefully
-		entries := storage.LoadLog(tmpDir)
+		entries, _, _ := storage.LoadLog(tmpDir)
 		
 		// Should only load the valid entries
 		validEntries := 0
@@ -73,7 +73,7 @@ Another Valid Habit: 3/7
 		}
 
 		// LoadHabitsConfig should now handle malformed entries gracefully
-		habits, maxLength := storage.LoadHabitsConfig(tmpDir)
+		habits, maxLength, _, _ := storage.LoadHabitsConfig(tmpDir)
 		
 		// Should only load the valid habits
 		if len(habits) < 2 {
@@ -102,7 +102,7 @@ Another Valid Habit: 3/7
 		os.RemoveAll(nonExistentDir)
 		
 		testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
-		err := storage.WriteHabitLog(nonExistentDir, testDate, "Test Habit", "y", "Test", "1.0")
+		err := storage.WriteHabitLog(nonExistentDir, testDate, "Test Habit", "y", "Test", "1.0", "")
 		
 		if err == nil {
 			t.Error("Expected error when writing to non-existent directory")
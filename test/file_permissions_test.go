@@ -37,7 +37,7 @@ func TestReadOnlyConfigDirectory(t *testing.T) {
 
 	// Test writing to read-only directory
 	testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
-	err = storage.WriteHabitLog(tmpDir, testDate, "Test Habit", "y", "Should fail", "1.0")
+	err = storage.WriteHabitLog(tmpDir, testDate, "Test Habit", "y", "Should fail", "1.0", "")
 	
 	if err == nil {
 		t.Error("Expected error when writing to read-only directory, but got none")
@@ -75,14 +75,14 @@ func TestReadOnlyLogFile(t *testing.T) {
 
 	// Test writing to read-only log file
 	testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
-	err = storage.WriteHabitLog(tmpDir, testDate, "Test Habit", "y", "Should fail", "1.0")
+	err = storage.WriteHabitLog(tmpDir, testDate, "Test Habit", "y", "Should fail", "1.0", "")
 	
 	if err == nil {
 		t.Error("Expected error when writing to read-only log file, but got none")
 	}
 
 	// Test that we can still read from read-only log file
-	entries := storage.LoadLog(tmpDir)
+	entries, _, _ := storage.LoadLog(tmpDir)
 	if entries == nil {
 		t.Error("Should be able to read from read-only log file")
 	}
@@ -97,17 +97,16 @@ func TestMissingConfigDirectory(t *testing.T) {
 	
 	// Test writing to non-existent directory
 	testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
-	err := storage.WriteHabitLog(nonExistentDir, testDate, "Test Habit", "y", "Should fail", "1.0")
+	err := storage.WriteHabitLog(nonExistentDir, testDate, "Test Habit", "y", "Should fail", "1.0", "")
 	
 	if err == nil {
 		t.Error("Expected error when writing to non-existent directory, but got none")
 	}
 
-	// Document the behavior instead of actually calling LoadLog
-	// since it calls log.Fatal on missing files
-	t.Log("⚠️  LoadLog calls log.Fatal when directory or log file doesn't exist")
-	t.Log("This means the application exits rather than returning an error")
-	t.Log("For better error handling, LoadLog should return an error instead of calling log.Fatal")
+	// LoadLog reports the missing directory as an error instead of exiting
+	if _, _, err := storage.LoadLog(nonExistentDir); err == nil {
+		t.Error("Expected error when loading log from non-existent directory, but got none")
+	}
 }
 
 func TestCloudStorageScenarios(t *testing.T) {
@@ -197,7 +196,7 @@ func TestCloudStorageScenarios(t *testing.T) {
 				// For scenarios that should fail, we expect log.Fatal which we can't easily test
 				t.Logf("⚠️  Scenario '%s' would cause log.Fatal - %s", scenario.name, scenario.description)
 			} else {
-				habits, _ := storage.LoadHabitsConfig(testDir)
+				habits, _, _, _ := storage.LoadHabitsConfig(testDir)
 				if len(habits) == 0 {
 					t.Errorf("Expected %s to work, but got no habits", scenario.description)
 				}
@@ -206,7 +205,7 @@ func TestCloudStorageScenarios(t *testing.T) {
 			// Test writing log entry (only for non-failing scenarios)
 			if !scenario.expectError {
 				testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
-				err = storage.WriteHabitLog(testDir, testDate, "Test Habit", "y", "Cloud test", "1.0")
+				err = storage.WriteHabitLog(testDir, testDate, "Test Habit", "y", "Cloud test", "1.0", "")
 				
 				if err != nil {
 					t.Errorf("Unexpected error for %s: %v", scenario.description, err)
@@ -232,7 +231,7 @@ func TestSlowFileSystem(t *testing.T) {
 
 	// Measure normal operation time
 	start := time.Now()
-	habits, _ := storage.LoadHabitsConfig(tmpDir)
+	habits, _, _, _ := storage.LoadHabitsConfig(tmpDir)
 	normalLoadTime := time.Since(start)
 
 	if len(habits) == 0 {
@@ -243,7 +242,7 @@ func TestSlowFileSystem(t *testing.T) {
 	start = time.Now()
 	for i := 0; i < 5; i++ {
 		testDate := civil.Date{Year: 2025, Month: 1, Day: 15 + i}
-		err := storage.WriteHabitLog(tmpDir, testDate, "Test Habit", "y", "Rapid test", "1.0")
+		err := storage.WriteHabitLog(tmpDir, testDate, "Test Habit", "y", "Rapid test", "1.0", "")
 		if err != nil {
 			t.Errorf("Failed rapid write #%d: %v", i, err)
 		}
@@ -256,7 +255,7 @@ func TestSlowFileSystem(t *testing.T) {
 	t.Logf("Average write time: %v", rapidWriteTime/5)
 
 	// Verify all entries were written
-	entries := storage.LoadLog(tmpDir)
+	entries, _, _ := storage.LoadLog(tmpDir)
 	if len(*entries) < 5 {
 		t.Errorf("Expected at least 5 entries, got %d", len(*entries))
 	}
@@ -287,7 +286,7 @@ func TestFileSystemRaceConditions(t *testing.T) {
 	go func() {
 		defer func() { done <- true }()
 		for i := 0; i < 10; i++ {
-			entries := storage.LoadLog(tmpDir)
+			entries, _, _ := storage.LoadLog(tmpDir)
 			if entries == nil {
 				errors <- err
 			}
@@ -300,7 +299,7 @@ func TestFileSystemRaceConditions(t *testing.T) {
 		defer func() { done <- true }()
 		for i := 0; i < 5; i++ {
 			testDate := civil.Date{Year: 2025, Month: 1, Day: 15 + i}
-			err := storage.WriteHabitLog(tmpDir, testDate, "Concurrent Test", "y", "Race test", "1.0")
+			err := storage.WriteHabitLog(tmpDir, testDate, "Concurrent Test", "y", "Race test", "1.0", "")
 			if err != nil {
 				errors <- err
 			}
@@ -327,7 +326,7 @@ func TestFileSystemRaceConditions(t *testing.T) {
 	}
 
 	// Verify final state
-	entries := storage.LoadLog(tmpDir)
+	entries, _, _ := storage.LoadLog(tmpDir)
 	if len(*entries) < 5 {
 		t.Errorf("Expected at least 5 entries after concurrent operations, got %d", len(*entries))
 	}
@@ -362,13 +361,13 @@ func TestCloudSyncConflicts(t *testing.T) {
 
 	// Test that normal operations still work with conflict files present
 	testDate := civil.Date{Year: 2025, Month: 1, Day: 20}
-	err = storage.WriteHabitLog(tmpDir, testDate, "Normal Operation", "y", "Should work", "1.0")
+	err = storage.WriteHabitLog(tmpDir, testDate, "Normal Operation", "y", "Should work", "1.0", "")
 	if err != nil {
 		t.Errorf("Normal operation failed with conflict files present: %v", err)
 	}
 
 	// Test loading still works
-	entries := storage.LoadLog(tmpDir)
+	entries, _, _ := storage.LoadLog(tmpDir)
 	if entries == nil {
 		t.Error("Failed to load log with conflict files present")
 	}
@@ -428,19 +427,19 @@ func TestTemporaryFileIssues(t *testing.T) {
 	}
 
 	// Test that operations work normally with temp files present
-	habits, _ := storage.LoadHabitsConfig(tmpDir)
+	habits, _, _, _ := storage.LoadHabitsConfig(tmpDir)
 	if len(habits) == 0 {
 		t.Error("Failed to load habits with temporary files present")
 	}
 
-	entries := storage.LoadLog(tmpDir)
+	entries, _, _ := storage.LoadLog(tmpDir)
 	if entries == nil {
 		t.Error("Failed to load log with temporary files present")
 	}
 
 	// Test writing works
 	testDate := civil.Date{Year: 2025, Month: 1, Day: 25}
-	err = storage.WriteHabitLog(tmpDir, testDate, "Temp File Test", "y", "Works", "1.0")
+	err = storage.WriteHabitLog(tmpDir, testDate, "Temp File Test", "y", "Works", "1.0", "")
 	if err != nil {
 		t.Errorf("Write failed with temp files present: %v", err)
 	}
@@ -946,4 +946,363 @@ func TestGraphIsInSkipPeriod(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestGraphAmountHabits(t *testing.T) {
+	day := func(d int) civil.Date { return civil.Date{Year: 2025, Month: 1, Day: d} }
+	water := &storage.Habit{Name: "Water", Target: 1, Interval: 1, Amount: 8, FirstRecord: day(1)}
+	run := &storage.Habit{Name: "Run", Target: 1, Interval: 7, Amount: 20, FirstRecord: day(1)}
+	entries := storage.Entries{
+		storage.DailyHabit{Day: day(1), Habit: "Water"}: {Result: "y", Amount: 8},
+		storage.DailyHabit{Day: day(2), Habit: "Water"}: {Result: "y", Amount: 10},
+		storage.DailyHabit{Day: day(3), Habit: "Water"}: {Result: "y", Amount: 5},
+		storage.DailyHabit{Day: day(4), Habit: "Water"}: {Result: "y", Amount: 8},
+		storage.DailyHabit{Day: day(1), Habit: "Run"}:   {Result: "y", Amount: 10},
+		storage.DailyHabit{Day: day(4), Habit: "Run"}:   {Result: "y", Amount: 12},
+		storage.DailyHabit{Day: day(9), Habit: "Run"}:   {Result: "y", Amount: 5},
+	}
+
+	satisfied := []struct {
+		habit    *storage.Habit
+		d        int
+		expected bool
+	}{
+		{water, 2, true},
+		{water, 3, false}, // 5 of 8 glasses
+		{run, 1, true},    // 10 + 12 within the window of days 1-7
+		{run, 6, true},
+		{run, 9, false}, // 12 + 5 within the window of days 4-10
+	}
+	for _, tt := range satisfied {
+		if got := graph.Satisfied(day(tt.d), tt.habit, entries); got != tt.expected {
+			t.Errorf("Satisfied(%s, day %d) = %v, want %v", tt.habit.Name, tt.d, got, tt.expected)
+		}
+	}
+
+	if graph.Warning(day(5), run, entries) {
+		t.Error("Warning(Run, day 5) = true, want false while 22km are in the window")
+	}
+	if !graph.Warning(day(8), run, entries) {
+		t.Error("Warning(Run, day 8) = false, want true once the window holds 12km")
+	}
+
+	// A partly filled window warns until the rest of the target is logged
+	read := &storage.Habit{Name: "Read", Target: 1, Interval: 7, Amount: 100, FirstRecord: day(1)}
+	entries[storage.DailyHabit{Day: day(2), Habit: "Read"}] = storage.Outcome{Result: "y", Amount: 60}
+	entries[storage.DailyHabit{Day: day(7), Habit: "Read"}] = storage.Outcome{Result: "y", Amount: 40}
+	readWarnings := []struct {
+		d        int
+		expected bool
+	}{
+		{5, false}, // the window of days 0-6 starts before the first record
+		{6, true},  // 60 of 100 pages with only day 7 left in days 1-7
+		{7, false}, // 100 pages over days 2-7
+		{8, true},  // 40 of 100 pages with only day 9 left in days 3-9
+	}
+	for _, tt := range readWarnings {
+		if got := graph.Warning(day(tt.d), read, entries); got != tt.expected {
+			t.Errorf("Warning(Read, day %d) = %v, want %v", tt.d, got, tt.expected)
+		}
+	}
+
+	// 22km over days 1-4 keep the target met through day 7
+	if got := graph.DaysUntilStreakBreak(day(4), run, entries); got != 4 {
+		t.Errorf("DaysUntilStreakBreak(Run, day 4) = %d, want 4", got)
+	}
+	if got := graph.DaysUntilStreakBreak(day(2), water, entries); got != 1 {
+		t.Errorf("DaysUntilStreakBreak(Water, day 2) = %d, want 1", got)
+	}
+	if got := graph.DaysUntilStreakBreak(day(3), water, entries); got != 0 {
+		t.Errorf("DaysUntilStreakBreak(Water, day 3) = %d, want 0", got)
+	}
+
+	current, longest := graph.StreakLengths(day(4), water, entries)
+	if current != 1 || longest != 2 {
+		t.Errorf("StreakLengths(Water) = %d, %d, want 1, 2", current, longest)
+	}
+
+	if got := graph.Score(day(3), []*storage.Habit{water}, &entries); got != 0 {
+		t.Errorf("Score(day 3) = %v, want 0 for a partial amount", got)
+	}
+	if got := graph.Score(day(4), []*storage.Habit{water}, &entries); got != 100 {
+		t.Errorf("Score(day 4) = %v, want 100", got)
+	}
+}
+
+func TestGraphAvoidHabits(t *testing.T) {
+	day := func(d int) civil.Date { return civil.Date{Year: 2025, Month: 1, Day: d} }
+	never := &storage.Habit{Name: "Coffee", Target: 1, Interval: 1, Avoid: true, FirstRecord: day(1)}
+	quota := &storage.Habit{Name: "Takeaway", Target: 1, Interval: 7, Avoid: true, Limit: 2, FirstRecord: day(1)}
+	entries := storage.Entries{
+		storage.DailyHabit{Day: day(1), Habit: "Coffee"}:    {Result: "n"},
+		storage.DailyHabit{Day: day(4), Habit: "Coffee"}:    {Result: "y"},
+		storage.DailyHabit{Day: day(1), Habit: "Takeaway"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(3), Habit: "Takeaway"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(6), Habit: "Takeaway"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(11), Habit: "Takeaway"}: {Result: "y"},
+	}
+
+	overLimit := []struct {
+		habit    *storage.Habit
+		d        int
+		expected bool
+	}{
+		{never, 1, false},
+		{never, 4, true},
+		{never, 5, false},
+		{quota, 3, false},  // second within the 7 days
+		{quota, 6, true},   // third within days 0-6
+		{quota, 11, false}, // days 5-11 hold two
+	}
+	for _, tt := range overLimit {
+		if got := graph.OverLimit(day(tt.d), tt.habit, entries); got != tt.expected {
+			t.Errorf("OverLimit(%s, day %d) = %v, want %v", tt.habit.Name, tt.d, got, tt.expected)
+		}
+	}
+
+	if !graph.Warning(day(4), quota, entries) {
+		t.Error("Warning(Takeaway, day 4) = false, want true with the allowance used up")
+	}
+	if graph.Warning(day(10), quota, entries) {
+		t.Error("Warning(Takeaway, day 10) = true, want false with one of two used")
+	}
+	if graph.Warning(day(5), never, entries) {
+		t.Error("Warning(Coffee, day 5) = true, want false for a habit never allowed")
+	}
+
+	if got := graph.DaysUntilStreakBreak(day(4), never, entries); got != -999 {
+		t.Errorf("DaysUntilStreakBreak(Coffee, day 4) = %d, want -999", got)
+	}
+	if got := graph.DaysUntilStreakBreak(day(5), never, entries); got != -1 {
+		t.Errorf("DaysUntilStreakBreak(Coffee, day 5) = %d, want -1", got)
+	}
+	if got := graph.DaysUntilStreakBreak(day(4), quota, entries); got != 0 {
+		t.Errorf("DaysUntilStreakBreak(Takeaway, day 4) = %d, want 0", got)
+	}
+
+	current, longest := graph.StreakLengths(day(8), never, entries)
+	if current != 4 || longest != 4 {
+		t.Errorf("StreakLengths(Coffee) = %d, %d, want 4, 4", current, longest)
+	}
+	current, longest = graph.StreakLengths(day(12), quota, entries)
+	if current != 6 || longest != 6 {
+		t.Errorf("StreakLengths(Takeaway) = %d, %d, want 6, 6", current, longest)
+	}
+
+	habits := []*storage.Habit{never, quota}
+	if got := graph.Score(day(3), habits, &entries); got != 100 {
+		t.Errorf("Score(day 3) = %v, want 100", got)
+	}
+	if got := graph.Score(day(4), habits, &entries); got != 50 {
+		t.Errorf("Score(day 4) = %v, want 50", got)
+	}
+}
+
+func TestGraphAvoidHabitWithoutEntries(t *testing.T) {
+	now := civil.DateOf(time.Now())
+	coffee := &storage.Habit{Name: "Coffee", Target: 1, Interval: 1, Avoid: true}
+	takeaway := &storage.Habit{Name: "Takeaway", Target: 1, Interval: 7, Avoid: true, Limit: 2, StartRecord: now.AddDays(-3)}
+	gym := &storage.Habit{Name: "Gym", Target: 1, Interval: 1}
+	entries := &storage.Entries{
+		storage.DailyHabit{Day: now.AddDays(-9), Habit: "Gym"}: {Result: "y"},
+	}
+	habits := []*storage.Habit{coffee, takeaway, gym}
+	entries.FirstRecords(now.AddDays(-365), now, habits)
+
+	if coffee.FirstRecord != now.AddDays(-9) {
+		t.Errorf("Expected Coffee to count from the first day in the log, got %s", coffee.FirstRecord)
+	}
+	if takeaway.FirstRecord != now.AddDays(-3) {
+		t.Errorf("Expected Takeaway to count from its start date, got %s", takeaway.FirstRecord)
+	}
+
+	current, longest := graph.StreakLengths(now, coffee, *entries)
+	if current != 10 || longest != 10 {
+		t.Errorf("StreakLengths(Coffee) = %d, %d, want 10, 10", current, longest)
+	}
+	current, _ = graph.StreakLengths(now, takeaway, *entries)
+	if current != 4 {
+		t.Errorf("StreakLengths(Takeaway) = %d, want 4", current)
+	}
+	if got := graph.Score(now, []*storage.Habit{coffee, takeaway}, entries); got != 100 {
+		t.Errorf("Score() = %v, want 100 for habits to avoid left alone", got)
+	}
+	if graphResult := graph.BuildGraph(coffee, entries, 20, false); strings.Count(graphResult, "━") != 10 {
+		t.Errorf("Graph %q should show every day since the first in the log as kept", graphResult)
+	}
+}
+
+func TestGraphNotStartedHabit(t *testing.T) {
+	now := civil.DateOf(time.Now())
+	habit := &storage.Habit{
+		Name:        "Planned",
+		Target:      1,
+		Interval:    1,
+		StartRecord: now.AddDays(-3),
+		FirstRecord: now.AddDays(-3),
+	}
+	entries := &storage.Entries{
+		storage.DailyHabit{Day: now.AddDays(-1), Habit: "Planned"}: {Result: "y"},
+	}
+
+	graphResult := graph.BuildGraph(habit, entries, 10, false)
+	if strings.Count(graphResult, "┈") != 7 {
+		t.Errorf("Graph %q should show the 7 days before the start date as not started", graphResult)
+	}
+	if !strings.Contains(graphResult, "!") {
+		t.Errorf("Graph %q should warn from the start date on", graphResult)
+	}
+
+	if score := graph.Score(now.AddDays(-5), []*storage.Habit{habit}, entries); score != 0 {
+		t.Errorf("Score before the start date = %v, want 0 with nothing to score", score)
+	}
+
+	habit.StartRecord = now.AddDays(2)
+	habit.FirstRecord = habit.StartRecord
+	graphResult = graph.BuildGraph(habit, entries, 10, false)
+	if strings.Contains(graphResult, "!") || strings.Contains(graphResult, "━") {
+		t.Errorf("Graph %q of a habit yet to start should show no warnings or entries", graphResult)
+	}
+}
+
+func TestGraphPausedHabit(t *testing.T) {
+	day := func(d int) civil.Date { return civil.Date{Year: 2025, Month: 7, Day: d} }
+	daily := &storage.Habit{
+		Name: "Read", Target: 1, Interval: 1, FirstRecord: day(1),
+		GroupPauses: []storage.Pause{{From: day(5), To: day(9)}},
+	}
+	entries := storage.Entries{
+		storage.DailyHabit{Day: day(1), Habit: "Read"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(2), Habit: "Read"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(3), Habit: "Read"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(4), Habit: "Read"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(10), Habit: "Read"}: {Result: "y"},
+	}
+
+	if graph.Warning(day(7), daily, entries) {
+		t.Error("Warning() should not warn on a paused day")
+	}
+	if got := graph.DaysUntilStreakBreak(day(9), daily, entries); got != 1 {
+		t.Errorf("DaysUntilStreakBreak() on the last paused day = %d, want 1", got)
+	}
+
+	// Paused days neither build nor break the streak
+	current, longest := graph.StreakLengths(day(10), daily, entries)
+	if current != 5 || longest != 5 {
+		t.Errorf("StreakLengths() = %d, %d, want 5, 5", current, longest)
+	}
+
+	// A paused habit is left out of the score like a skipped one
+	other := &storage.Habit{Name: "Gym", Target: 1, Interval: 1, FirstRecord: day(1)}
+	entries[storage.DailyHabit{Day: day(6), Habit: "Gym"}] = storage.Outcome{Result: "y"}
+	if got := graph.Score(day(6), []*storage.Habit{daily, other}, &entries); got != 100 {
+		t.Errorf("Score() on a paused day = %v, want 100", got)
+	}
+
+	now := civil.DateOf(time.Now())
+	daily.FirstRecord = now.AddDays(-10)
+	daily.GroupPauses = []storage.Pause{{From: now.AddDays(-3), To: now.AddDays(-1)}}
+	graphResult := graph.BuildGraph(daily, &storage.Entries{}, 10, false)
+	if strings.Count(graphResult, "‖") != 3 {
+		t.Errorf("Graph %q should show the 3 paused days", graphResult)
+	}
+}
+
+func TestGraphWeekdayHabit(t *testing.T) {
+	// 2025-07-07 is a Monday
+	day := func(d int) civil.Date { return civil.Date{Year: 2025, Month: 7, Day: d} }
+	gym := &storage.Habit{
+		Name: "Gym", Target: 1, Interval: 1, FirstRecord: day(7),
+		Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+	}
+	entries := storage.Entries{
+		storage.DailyHabit{Day: day(7), Habit: "Gym"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(9), Habit: "Gym"}:  {Result: "y"},
+		storage.DailyHabit{Day: day(11), Habit: "Gym"}: {Result: "y"},
+	}
+
+	if graph.Warning(day(8), gym, entries) {
+		t.Error("Warning() should not warn on an off-day")
+	}
+	if !graph.Warning(day(14), gym, entries) {
+		t.Error("Warning() should warn on a due day with nothing logged")
+	}
+	if got := graph.DaysUntilStreakBreak(day(12), gym, entries); got != 2 {
+		t.Errorf("DaysUntilStreakBreak() on Saturday = %d, want 2 (to Monday)", got)
+	}
+	if got := graph.DaysUntilStreakBreak(day(14), gym, entries); got != 0 {
+		t.Errorf("DaysUntilStreakBreak() on a due Monday = %d, want 0", got)
+	}
+	if got := graph.DaysUntilStreakBreak(day(15), gym, entries); got != -999 {
+		t.Errorf("DaysUntilStreakBreak() after a missed Monday = %d, want -999", got)
+	}
+
+	// Off-days neither build nor break the streak
+	current, longest := graph.StreakLengths(day(13), gym, entries)
+	if current != 3 || longest != 3 {
+		t.Errorf("StreakLengths() = %d, %d, want 3, 3", current, longest)
+	}
+
+	// An off-day is left out of the score
+	other := &storage.Habit{Name: "Read", Target: 1, Interval: 1, FirstRecord: day(7)}
+	entries[storage.DailyHabit{Day: day(8), Habit: "Read"}] = storage.Outcome{Result: "y"}
+	if got := graph.Score(day(8), []*storage.Habit{gym, other}, &entries); got != 100 {
+		t.Errorf("Score() on an off-day = %v, want 100", got)
+	}
+}
+
+func TestGraphCalendarPeriodHabit(t *testing.T) {
+	date := func(m time.Month, d int) civil.Date { return civil.Date{Year: 2025, Month: m, Day: d} }
+
+	// 2025-07-09 is a Wednesday
+	weekly := &storage.Habit{Name: "Plan", Target: 1, Interval: 7, Period: "week"}
+	if from, to := graph.PeriodBounds(date(7, 9), weekly); from != date(7, 7) || to != date(7, 13) {
+		t.Errorf("PeriodBounds() week = %v..%v, want 2025-07-07..2025-07-13", from, to)
+	}
+	if err := graph.UseWeekStart("sunday"); err != nil {
+		t.Fatal(err)
+	}
+	defer graph.UseWeekStart("monday")
+	if from, to := graph.PeriodBounds(date(7, 9), weekly); from != date(7, 6) || to != date(7, 12) {
+		t.Errorf("PeriodBounds() week starting sunday = %v..%v, want 2025-07-06..2025-07-12", from, to)
+	}
+	quarterly := &storage.Habit{Name: "Taxes", Target: 1, Interval: 91, Period: "quarter"}
+	if from, to := graph.PeriodBounds(date(8, 15), quarterly); from != date(7, 1) || to != date(9, 30) {
+		t.Errorf("PeriodBounds() quarter = %v..%v, want 2025-07-01..2025-09-30", from, to)
+	}
+
+	monthly := &storage.Habit{Name: "Budget", Target: 2, Interval: 30, Period: "month", FirstRecord: date(6, 3)}
+	entries := storage.Entries{
+		storage.DailyHabit{Day: date(6, 3), Habit: "Budget"}:  {Result: "y"},
+		storage.DailyHabit{Day: date(6, 20), Habit: "Budget"}: {Result: "y"},
+		storage.DailyHabit{Day: date(7, 10), Habit: "Budget"}: {Result: "y"},
+	}
+
+	if !graph.Satisfied(date(6, 25), monthly, entries) {
+		t.Error("Satisfied() should be true within a month that met its target")
+	}
+	// A rolling 30 days would hold June 20 and July 10, but July starts afresh
+	if graph.Satisfied(date(7, 12), monthly, entries) {
+		t.Error("Satisfied() should not count the previous month")
+	}
+	if graph.Warning(date(6, 28), monthly, entries) {
+		t.Error("Warning() should not warn in the month first recorded")
+	}
+	if graph.Warning(date(7, 20), monthly, entries) {
+		t.Error("Warning() should not warn with most of the month left")
+	}
+	if !graph.Warning(date(7, 28), monthly, entries) {
+		t.Error("Warning() should warn near the end of a month not met")
+	}
+	if got := graph.DaysUntilStreakBreak(date(7, 20), monthly, entries); got != 11 {
+		t.Errorf("DaysUntilStreakBreak() = %d, want 11 (to the end of July)", got)
+	}
+	if got := graph.DaysUntilStreakBreak(date(8, 5), monthly, entries); got != -999 {
+		t.Errorf("DaysUntilStreakBreak() after a month not met = %d, want -999", got)
+	}
+
+	entries[storage.DailyHabit{Day: date(7, 25), Habit: "Budget"}] = storage.Outcome{Result: "y"}
+	if got := graph.DaysUntilStreakBreak(date(7, 26), monthly, entries); got != 36 {
+		t.Errorf("DaysUntilStreakBreak() once July is met = %d, want 36 (to the end of August)", got)
+	}
+}
@@ -494,8 +494,8 @@ func TestNewHabitIntegration(t *testing.T) {
 
 	// Load initial configuration using component functions directly
 	// to avoid terminal size issues in tests
-	habits, maxHabitNameLength := storage.LoadHabitsConfig(tmpDir)
-	entries := storage.LoadLog(tmpDir)
+	habits, maxHabitNameLength, _, _ := storage.LoadHabitsConfig(tmpDir)
+	entries, _, _ := storage.LoadLog(tmpDir)
 	now := civil.DateOf(time.Now())
 	to := now
 	from := to.AddDays(-365 * 5)
@@ -528,8 +528,8 @@ func TestNewHabitIntegration(t *testing.T) {
 	}
 
 	// Reload configuration
-	habits, maxHabitNameLength = storage.LoadHabitsConfig(tmpDir)
-	entries = storage.LoadLog(tmpDir)
+	habits, maxHabitNameLength, _, _ = storage.LoadHabitsConfig(tmpDir)
+	entries, _, _ = storage.LoadLog(tmpDir)
 	entries.FirstRecords(from, to, habits)
 	
 	harsh = &internal.Harsh{
@@ -31,7 +31,11 @@ func TestFullWorkflow(t *testing.T) {
 	storage.CreateNewLogFile(tmpDir)
 
 	// Step 2: Create Harsh instance
-	harsh := internal.NewHarsh()
+	settings := storage.DefaultSettings()
+	harsh, err := internal.NewHarsh(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir}, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Verify initialization
 	if harsh == nil {
@@ -52,12 +56,12 @@ func TestFullWorkflow(t *testing.T) {
 	testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
 	repository := harsh.GetRepository()
 	
-	err = repository.WriteEntry(testDate, habits[0].Name, "y", "Test entry", "1.0")
+	err = repository.WriteEntry(testDate, habits[0].Name, "y", "Test entry", "1.0", "")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = repository.WriteEntry(testDate, habits[1].Name, "n", "Missed it", "0")
+	err = repository.WriteEntry(testDate, habits[1].Name, "n", "Missed it", "0", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -169,7 +173,11 @@ Sleep tracking: 0
 	storage.CreateNewLogFile(tmpDir)
 
 	// Initialize Harsh
-	harsh := internal.NewHarsh()
+	settings := storage.DefaultSettings()
+	harsh, err := internal.NewHarsh(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir}, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
 	habits := harsh.GetHabits()
 	repository := harsh.GetRepository()
 
@@ -177,28 +185,28 @@ Sleep tracking: 0
 	startDate := civil.Date{Year: 2025, Month: 1, Day: 1}
 	
 	// Day 1: Good day
-	repository.WriteEntry(startDate, "Gym", "y", "Great workout", "1.5")
-	repository.WriteEntry(startDate, "Running", "n", "Too tired", "0")
-	repository.WriteEntry(startDate, "Stretching", "y", "Morning routine", "0.5")
-	repository.WriteEntry(startDate, "Daily standup", "y", "Good meeting", "0")
-	repository.WriteEntry(startDate, "Code review", "y", "Reviewed 3 PRs", "3")
-	repository.WriteEntry(startDate, "Water intake", "y", "8 glasses", "8")
-	repository.WriteEntry(startDate, "Sleep tracking", "y", "Tracked with app", "0")
+	repository.WriteEntry(startDate, "Gym", "y", "Great workout", "1.5", "")
+	repository.WriteEntry(startDate, "Running", "n", "Too tired", "0", "")
+	repository.WriteEntry(startDate, "Stretching", "y", "Morning routine", "0.5", "")
+	repository.WriteEntry(startDate, "Daily standup", "y", "Good meeting", "0", "")
+	repository.WriteEntry(startDate, "Code review", "y", "Reviewed 3 PRs", "3", "")
+	repository.WriteEntry(startDate, "Water intake", "y", "8 glasses", "8", "")
+	repository.WriteEntry(startDate, "Sleep tracking", "y", "Tracked with app", "0", "")
 
 	// Day 2: Mixed day
 	day2 := startDate.AddDays(1)
-	repository.WriteEntry(day2, "Gym", "n", "Rest day", "0")
-	repository.WriteEntry(day2, "Running", "y", "5k run", "5")
-	repository.WriteEntry(day2, "Stretching", "y", "10 min", "0.17")
-	repository.WriteEntry(day2, "Daily standup", "y", "Brief update", "0")
-	repository.WriteEntry(day2, "Code review", "s", "Skipped today", "0")
-	repository.WriteEntry(day2, "Water intake", "n", "Forgot to track", "0")
+	repository.WriteEntry(day2, "Gym", "n", "Rest day", "0", "")
+	repository.WriteEntry(day2, "Running", "y", "5k run", "5", "")
+	repository.WriteEntry(day2, "Stretching", "y", "10 min", "0.17", "")
+	repository.WriteEntry(day2, "Daily standup", "y", "Brief update", "0", "")
+	repository.WriteEntry(day2, "Code review", "s", "Skipped today", "0", "")
+	repository.WriteEntry(day2, "Water intake", "n", "Forgot to track", "0", "")
 
 	// Day 3: Poor day
 	day3 := startDate.AddDays(2)
-	repository.WriteEntry(day3, "Stretching", "n", "Overslept", "0")
-	repository.WriteEntry(day3, "Daily standup", "n", "Missed meeting", "0")
-	repository.WriteEntry(day3, "Water intake", "y", "Better today", "6")
+	repository.WriteEntry(day3, "Stretching", "n", "Overslept", "0", "")
+	repository.WriteEntry(day3, "Daily standup", "n", "Missed meeting", "0", "")
+	repository.WriteEntry(day3, "Water intake", "y", "Better today", "6", "")
 
 	// Reload entries
 	entries, err := repository.LoadEntries()
@@ -283,7 +291,11 @@ func TestConcurrentAccess(t *testing.T) {
 	storage.CreateExampleHabitsFile(tmpDir)
 	storage.CreateNewLogFile(tmpDir)
 
-	harsh := internal.NewHarsh()
+	settings := storage.DefaultSettings()
+	harsh, err := internal.NewHarsh(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir}, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
 	
 	// Test parallel graph building with many habits
 	manyHabits := make([]*storage.Habit, 100)
@@ -352,7 +364,7 @@ func TestErrorHandling(t *testing.T) {
 	}
 
 	// LoadLog should handle valid entries
-	entries := storage.LoadLog(tmpDir)
+	entries, _, _ := storage.LoadLog(tmpDir)
 	if len(*entries) != 2 {
 		t.Errorf("Expected 2 valid entries, got %d", len(*entries))
 	}
@@ -391,7 +403,11 @@ func TestPerformanceBaseline(t *testing.T) {
 	storage.CreateExampleHabitsFile(tmpDir)
 	storage.CreateNewLogFile(tmpDir)
 
-	harsh := internal.NewHarsh()
+	settings := storage.DefaultSettings()
+	harsh, err := internal.NewHarsh(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir}, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
 	repository := harsh.GetRepository()
 
 	// Add many entries across multiple days
@@ -408,7 +424,7 @@ func TestPerformanceBaseline(t *testing.T) {
 			} else if day%5 == 0 {
 				result = "s"
 			}
-			repository.WriteEntry(currentDate, habit.Name, result, "test", "1.0")
+			repository.WriteEntry(currentDate, habit.Name, result, "test", "1.0", "")
 		}
 	}
 	writeTime := time.Since(start)
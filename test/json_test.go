@@ -3,6 +3,7 @@ package test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -1252,3 +1253,118 @@ func TestShowHabitLogJSON_StreakLengths_EndedHabit(t *testing.T) {
 		t.Errorf("Expected longest_streak=5, got %d", h.LongestStreak)
 	}
 }
+
+func TestReadHabitLogJSON_RoundTrip(t *testing.T) {
+	now := civil.DateOf(time.Now())
+	start := now.AddDays(-200)
+	habits := []*storage.Habit{
+		{Name: "Gym", Heading: "Health", Frequency: "3/7", Target: 3, Interval: 7},
+		{Name: "Floss", Frequency: "1", Target: 1, Interval: 1, EndRecord: start.AddDays(2)},
+		{Name: "Unstarted", Frequency: "7", Target: 1, Interval: 7},
+	}
+	entries := &storage.Entries{
+		{Day: start, Habit: "Gym"}:             {Result: "y", Amount: 2.5, Comment: "legs: done", Time: "07:30"},
+		{Day: start.AddDays(150), Habit: "Gym"}: {Result: "n"},
+		{Day: now, Habit: "Gym"}:               {Result: "s"},
+		{Day: start, Habit: "Floss"}:           {Result: "y"},
+	}
+	entries.FirstRecords(now.AddDays(-365), now, habits)
+
+	var buf bytes.Buffer
+	if err := ui.WriteHabitLogJSON(&buf, habits, entries, "", false, true); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ui.ReadHabitLogJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != len(habits) {
+		t.Fatalf("Expected %d habits back, got %d", len(habits), len(imported))
+	}
+	total := 0
+	for i, ih := range imported {
+		h := habits[i]
+		if ih.Habit.Name != h.Name || ih.Habit.Heading != h.Heading || ih.Habit.Frequency != h.Frequency || ih.Habit.EndRecord != h.EndRecord {
+			t.Errorf("Habit %s did not round trip: %+v", h.Name, ih.Habit)
+		}
+		for day, outcome := range ih.Entries {
+			if want := (*entries)[storage.DailyHabit{Day: day, Habit: h.Name}]; outcome != want {
+				t.Errorf("Entry %s %s did not round trip: got %+v, want %+v", day, h.Name, outcome, want)
+			}
+			total++
+		}
+	}
+	if total != len(*entries) {
+		t.Errorf("Expected all %d entries back from the full history, got %d", len(*entries), total)
+	}
+
+	// The 100 day --json document only carries recent entries
+	buf.Reset()
+	if err := ui.WriteHabitLogJSON(&buf, habits, entries, "gym", false, false); err != nil {
+		t.Fatal(err)
+	}
+	imported, err = ui.ReadHabitLogJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 1 || len(imported[0].Entries) != 2 {
+		t.Errorf("Expected Gym's last 100 days back, got %+v", imported)
+	}
+}
+
+func TestReadHabitLogJSON_AliasesAndPauses(t *testing.T) {
+	now := civil.DateOf(time.Now())
+	gym := &storage.Habit{
+		Name: "Gym", Aliases: []string{"Workout", "Lift"}, Frequency: "1", Target: 1, Interval: 1,
+		Pauses:      []storage.Pause{{From: now.AddDays(-10), To: now.AddDays(-5)}},
+		GroupPauses: []storage.Pause{{From: now.AddDays(-2), To: now.AddDays(-2)}},
+	}
+	entries := &storage.Entries{
+		{Day: now.AddDays(-20), Habit: "Gym"}: {Result: "y"},
+	}
+	entries.FirstRecords(now.AddDays(-365), now, []*storage.Habit{gym})
+
+	var buf bytes.Buffer
+	if err := ui.WriteHabitLogJSON(&buf, []*storage.Habit{gym}, entries, "", false, true); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ui.ReadHabitLogJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := storage.PlanImport(imported, storage.ImportOptions{}, nil, storage.Entries{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.NewHabits) != 1 {
+		t.Fatalf("Expected Gym to be added, got %+v", plan.NewHabits)
+	}
+	want := fmt.Sprintf("Gym (was: Workout, Lift): 1 [pause=%s..%s,%s]", now.AddDays(-10), now.AddDays(-5), now.AddDays(-2))
+	if got := storage.FormatHabit(plan.NewHabits[0]); got != want {
+		t.Errorf("Expected the imported habit line %q, got %q", want, got)
+	}
+}
+
+func TestReadHabitLogJSON_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"Not JSON", `habits`},
+		{"No habits", `{"date": "2025-01-01"}`},
+		{"Missing frequency", `{"habits": [{"name": "Gym"}]}`},
+		{"Invalid date", `{"habits": [{"name": "Gym", "frequency": "1", "entries": [{"date": "01/02/2025", "result": "y"}]}]}`},
+		{"Invalid result", `{"habits": [{"name": "Gym", "frequency": "1", "entries": [{"date": "2025-01-02", "result": "x"}]}]}`},
+		{"Invalid time", `{"habits": [{"name": "Gym", "frequency": "1", "entries": [{"date": "2025-01-02", "result": "y", "time": "25:00"}]}]}`},
+		{"Invalid pause", `{"habits": [{"name": "Gym", "frequency": "1", "pauses": ["2025-01-05..2025-01-02"]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ui.ReadHabitLogJSON(bytes.NewBufferString(tt.doc)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package test
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/civil"
 	"github.com/wakatara/harsh/internal/storage"
@@ -24,20 +28,26 @@ func TestHabitParseHabitFrequency(t *testing.T) {
 		{"Tracking only", "0", 0, 1, false},
 		{"Monthly habit", "30", 1, 30, false},
 		{"Twice daily", "2/2", 2, 2, false},
+		{"Non-integer target", "x/7", 0, 0, true},
+		{"Zero interval", "3/0", 0, 0, true},
+		{"Target exceeds interval", "8/7", 0, 0, true},
+		{"Invalid suffix", "1m", 0, 0, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &storage.Habit{Name: "Test", Frequency: tt.frequency}
-			
-			// Capture exit calls for error cases
+
+			err := h.ParseHabitFrequency()
 			if tt.shouldErr {
-				// Note: In a real implementation, we'd refactor ParseHabitFrequency 
-				// to return an error instead of calling os.Exit
+				if err == nil {
+					t.Errorf("Expected error for frequency %q, got target=%d interval=%d", tt.frequency, h.Target, h.Interval)
+				}
 				return
 			}
-			
-			h.ParseHabitFrequency()
+			if err != nil {
+				t.Fatalf("Unexpected error for frequency %q: %v", tt.frequency, err)
+			}
 			if h.Target != tt.target || h.Interval != tt.interval {
 				t.Errorf("got target=%d interval=%d, want target=%d interval=%d",
 					h.Target, h.Interval, tt.target, tt.interval)
@@ -71,7 +81,13 @@ Sleep tracking: 0
 		t.Fatal(err)
 	}
 
-	habits, maxLength := storage.LoadHabitsConfig(tmpDir)
+	habits, maxLength, warnings, err := storage.LoadHabitsConfig(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
 
 	// Verify habits were loaded correctly
 	if len(habits) != 5 {
@@ -116,7 +132,13 @@ func TestLoadLog(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	entries := storage.LoadLog(tmpDir)
+	entries, warnings, err := storage.LoadLog(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
 
 	// Verify entries were loaded correctly
 	if len(*entries) != 5 {
@@ -139,6 +161,74 @@ func TestLoadLog(t *testing.T) {
 	}
 }
 
+func TestLoadLogWarnings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logFile := filepath.Join(tmpDir, "log")
+	logContent := `2025-01-01 : Gym : y : Great workout : 1.5
+2025-01-01 Gym y
+2025-01-02 : Gym : x
+`
+	if err := os.WriteFile(logFile, []byte(logContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, warnings, err := storage.LoadLog(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*entries) != 1 {
+		t.Errorf("Expected 1 entry, got %d", len(*entries))
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %d: %v", len(warnings), warnings)
+	}
+
+	w := warnings[0]
+	if w.File != logFile || w.Line != 2 || w.Text != "2025-01-01 Gym y" || w.Reason == "" {
+		t.Errorf("Warning fields incorrect: %+v", w)
+	}
+	if !strings.HasPrefix(w.Error(), logFile+":2: ") {
+		t.Errorf("Warning should start with file:line, got %q", w.Error())
+	}
+	if warnings[1].Line != 3 {
+		t.Errorf("Second warning should be on line 3, got %d", warnings[1].Line)
+	}
+}
+
+func TestLoadLogMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entries, _, err := storage.LoadLog(tmpDir)
+	if err == nil {
+		t.Fatal("Expected error for missing log file")
+	}
+	if entries != nil {
+		t.Error("Entries should be nil when the log cannot be read")
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected error to wrap fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestLoadHabitsConfigInvalidEndDate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	habitsFile := filepath.Join(tmpDir, "habits")
+	if err := os.WriteFile(habitsFile, []byte("Good: 1\nBad: 1: 2024-13-45\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, err := storage.LoadHabitsConfig(tmpDir)
+	var parseErr *storage.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *storage.ParseError, got %v", err)
+	}
+	if parseErr.Line != 2 || parseErr.Text != "Bad: 1: 2024-13-45" {
+		t.Errorf("Parse error fields incorrect: %+v", parseErr)
+	}
+}
+
 func TestWriteHabitLog(t *testing.T) {
 	// Create temporary directory for test
 	tmpDir, err := os.MkdirTemp("", "harsh_storage_test")
@@ -149,7 +239,7 @@ func TestWriteHabitLog(t *testing.T) {
 
 	// Test writing log entry
 	date := civil.Date{Year: 2025, Month: 1, Day: 15}
-	err = storage.WriteHabitLog(tmpDir, date, "Test Habit", "y", "Great job", "2.5")
+	err = storage.WriteHabitLog(tmpDir, date, "Test Habit", "y", "Great job", "2.5", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -217,7 +307,10 @@ func TestFileRepository(t *testing.T) {
 	storage.CreateNewLogFile(tmpDir)
 
 	// Test repository
-	repo := storage.NewFileRepository()
+	repo, err := storage.NewFileRepository(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Test GetConfigDir
 	if repo.GetConfigDir() != tmpDir {
@@ -250,7 +343,7 @@ func TestFileRepository(t *testing.T) {
 
 	// Test WriteEntry
 	testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
-	err = repo.WriteEntry(testDate, "Test Habit", "y", "Test comment", "1.0")
+	err = repo.WriteEntry(testDate, "Test Habit", "y", "Test comment", "1.0", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -382,7 +475,7 @@ Old habit: 7: 2023-12-31
 		t.Fatal(err)
 	}
 
-	habits, _ := storage.LoadHabitsConfig(tmpDir)
+	habits, _, _, _ := storage.LoadHabitsConfig(tmpDir)
 
 	// Verify habits were loaded correctly
 	if len(habits) != 4 {
@@ -510,4 +603,1663 @@ func TestHabitIsEnded(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestFindHabit(t *testing.T) {
+	habits := []*storage.Habit{
+		{Name: "Gym"},
+		{Name: "Gym class"},
+		{Name: "Called Mom"},
+		{Name: "Called Dad"},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"Exact match", "Gym", "Gym", false},
+		{"Exact match wins over fragment", "gym", "Gym", false},
+		{"Unique fragment", "mom", "Called Mom", false},
+		{"Ambiguous fragment", "called", "", true},
+		{"Unknown habit", "Swim", "", true},
+		{"Empty name", "  ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			habit, err := storage.FindHabit(habits, tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %s", tt.query, habit.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for %q: %v", tt.query, err)
+			}
+			if habit.Name != tt.want {
+				t.Errorf("FindHabit(%q) = %s, want %s", tt.query, habit.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateAndDeleteHabitLog(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logFile := filepath.Join(tmpDir, "log")
+	logContent := `# Comments survive
+2025-01-01 : Gym : y : first : 1
+2025-01-02 : Gym : n : superseded : 
+not a log line
+2025-01-02 : Read : y :  : 
+2025-01-02 : Gym : y : wins : 2
+`
+	if err := os.WriteFile(logFile, []byte(logContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	day := civil.Date{Year: 2025, Month: 1, Day: 2}
+	if err := storage.UpdateHabitLog(tmpDir, day, "Gym", "s", "travel", "3", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# Comments survive
+2025-01-01 : Gym : y : first : 1
+not a log line
+2025-01-02 : Read : y :  : 
+2025-01-02 : Gym : s : travel : 3
+`
+	if string(content) != expected {
+		t.Errorf("Updated log incorrect:\ngot  %q\nwant %q", string(content), expected)
+	}
+
+	if err := storage.DeleteHabitLog(tmpDir, day, "Read"); err != nil {
+		t.Fatal(err)
+	}
+	entries, _, err := storage.LoadLog(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := (*entries)[storage.DailyHabit{Day: day, Habit: "Read"}]; ok {
+		t.Error("Deleted entry should not be loaded")
+	}
+	if got := (*entries)[storage.DailyHabit{Day: day, Habit: "Gym"}]; got.Result != "s" || got.Amount != 3 {
+		t.Errorf("Unrelated entry changed: %+v", got)
+	}
+
+	err = storage.DeleteHabitLog(tmpDir, day, "Read")
+	if !errors.Is(err, storage.ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound deleting a missing entry, got %v", err)
+	}
+	err = storage.UpdateHabitLog(tmpDir, civil.Date{Year: 2024, Month: 1, Day: 1}, "Gym", "y", "", "", "")
+	if !errors.Is(err, storage.ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound updating a missing entry, got %v", err)
+	}
+}
+
+func TestUpdateAndDeleteArchivedEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logFile := filepath.Join(tmpDir, "log")
+	if err := os.WriteFile(logFile, []byte("2022-03-01 : Gym : y :  : \n2022-04-01 : Gym : y :  : \n2022-05-01 : Gym : y :  : \n2025-01-01 : Gym : y :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.ArchiveLog(tmpDir, civil.Date{Year: 2023, Month: 1, Day: 1}, true, false); err != nil {
+		t.Fatal(err)
+	}
+	// A copy left in the log wins over the archived entry
+	if err := os.WriteFile(logFile, []byte("2022-05-01 : Gym : s :  : \n2025-01-01 : Gym : y :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.UpdateHabitLog(tmpDir, civil.Date{Year: 2022, Month: 3, Day: 1}, "Gym", "n", "archived", "", ""); err != nil {
+		t.Fatalf("UpdateHabitLog() of an archived entry: %v", err)
+	}
+	if err := storage.DeleteHabitLog(tmpDir, civil.Date{Year: 2022, Month: 4, Day: 1}, "Gym"); err != nil {
+		t.Fatalf("DeleteHabitLog() of an archived entry: %v", err)
+	}
+	if err := storage.DeleteHabitLog(tmpDir, civil.Date{Year: 2022, Month: 5, Day: 1}, "Gym"); err != nil {
+		t.Fatalf("DeleteHabitLog() of an entry in the log and archive: %v", err)
+	}
+	if err := storage.DeleteHabitLog(tmpDir, civil.Date{Year: 2022, Month: 6, Day: 1}, "Gym"); !errors.Is(err, storage.ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for a day in neither, got %v", err)
+	}
+
+	archived, _, err := storage.LoadArchive(filepath.Join(tmpDir, "log.2022.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := storage.Outcome{Result: "n", Comment: "archived"}
+	if got := (*archived)[storage.DailyHabit{Day: civil.Date{Year: 2022, Month: 3, Day: 1}, Habit: "Gym"}]; len(*archived) != 1 || got != want {
+		t.Errorf("Expected only the updated entry in the archive, got %v", *archived)
+	}
+	if content, _ := os.ReadFile(logFile); string(content) != "2025-01-01 : Gym : y :  : \n" {
+		t.Errorf("Unexpected log:\n%s", content)
+	}
+}
+
+func TestTidyLog(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logFile := filepath.Join(tmpDir, "log")
+	original := `# Header comment
+2025-01-02 : Read : y :  : 
+2025-01-02 : Gym : n : superseded : 
+2025-01-01 : Gym : y : first : 1
+not a log line
+
+2025-01-02 : Gym : y : wins : 2
+2025-01-01 : Retired : y :  : 
+`
+	if err := os.WriteFile(logFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	habits := []*storage.Habit{{Name: "Gym"}, {Name: "Read"}}
+
+	t.Run("Dry run leaves log untouched", func(t *testing.T) {
+		result, err := storage.TidyLog(tmpDir, habits, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Only Read is out of place among the entries kept
+		if !result.Changed || result.Moved != 1 || len(result.Duplicates) != 1 || len(result.Orphans) != 1 {
+			t.Errorf("Unexpected dry run result: %+v", result)
+		}
+		diff := "--- " + logFile + "\n+++ " + logFile + ` (tidied)
+@@ -1,8 +1,5 @@
+ # Header comment
+-2025-01-02 : Read : y :  : 
+-2025-01-02 : Gym : n : superseded : 
+ 2025-01-01 : Gym : y : first : 1
+ not a log line
+-
+ 2025-01-02 : Gym : y : wins : 2
+-2025-01-01 : Retired : y :  : 
++2025-01-02 : Read : y :  : 
+`
+		if result.Diff != diff {
+			t.Errorf("Dry run diff incorrect:\ngot  %q\nwant %q", result.Diff, diff)
+		}
+		content, _ := os.ReadFile(logFile)
+		if string(content) != original {
+			t.Error("Dry run should not modify the log")
+		}
+		if fileExists(logFile + ".bak") {
+			t.Error("Dry run should not write a backup")
+		}
+	})
+
+	t.Run("Tidy sorts, deduplicates and keeps orphans", func(t *testing.T) {
+		result, err := storage.TidyLog(tmpDir, habits, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `# Header comment
+2025-01-01 : Gym : y : first : 1
+2025-01-01 : Retired : y :  : 
+not a log line
+2025-01-02 : Gym : y : wins : 2
+2025-01-02 : Read : y :  : 
+`
+		content, _ := os.ReadFile(logFile)
+		if string(content) != expected {
+			t.Errorf("Tidied log incorrect:\ngot  %q\nwant %q", string(content), expected)
+		}
+		backup, _ := os.ReadFile(result.BackupPath)
+		if string(backup) != original {
+			t.Error("Backup should contain the original log")
+		}
+		if result.Entries != 4 || len(result.Orphans) != 0 {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("Archive orphans", func(t *testing.T) {
+		result, err := storage.TidyLog(tmpDir, habits, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archive, _ := os.ReadFile(filepath.Join(tmpDir, "log.orphaned"))
+		if string(archive) != "2025-01-01 : Retired : y :  : \n" {
+			t.Errorf("Orphan archive incorrect: %q", string(archive))
+		}
+		content, _ := os.ReadFile(logFile)
+		if strings.Contains(string(content), "Retired") {
+			t.Error("Archived orphan should be removed from the log")
+		}
+		if result.ArchivePath == "" {
+			t.Error("ArchivePath should be set when orphans are archived")
+		}
+	})
+
+	t.Run("Tidy log is unchanged", func(t *testing.T) {
+		result, err := storage.TidyLog(tmpDir, habits, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Changed {
+			t.Errorf("Second tidy should not change the log: %+v", result)
+		}
+	})
+}
+
+func TestTidyLogKeepsCommentsWithTheirEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "log")
+	original := `2025-01-02 : Gym : n :  : 
+# Knee felt off
+2025-01-03 : Gym : y :  : 
+# Moved house
+2025-01-01 : Old : y :  : 
+2025-01-03 : Gym : s : retyped : 
+# Trailing note
+`
+	if err := os.WriteFile(logFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.TidyLog(tmpDir, []*storage.Habit{{Name: "Gym"}}, true, false); err != nil {
+		t.Fatal(err)
+	}
+	// The note on the superseded entry moves to the one replacing it
+	expected := `2025-01-02 : Gym : n :  : 
+# Knee felt off
+2025-01-03 : Gym : s : retyped : 
+# Trailing note
+`
+	content, _ := os.ReadFile(logFile)
+	if string(content) != expected {
+		t.Errorf("Tidied log incorrect:\ngot  %q\nwant %q", string(content), expected)
+	}
+	archive, _ := os.ReadFile(logFile + ".orphaned")
+	if string(archive) != "# Moved house\n2025-01-01 : Old : y :  : \n" {
+		t.Errorf("Orphan archive should keep its comment, got %q", string(archive))
+	}
+}
+
+func TestLockConfigDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := storage.CreateNewLogFile(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := storage.LockConfigDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes while the lock is held fail clearly instead of interleaving
+	testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
+	err = storage.WriteHabitLog(tmpDir, testDate, "Gym", "y", "", "", "")
+	if !errors.Is(err, storage.ErrLocked) {
+		t.Fatalf("Expected ErrLocked while lock is held, got %v", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.WriteHabitLog(tmpDir, testDate, "Gym", "y", "", "", ""); err != nil {
+		t.Fatalf("Write should succeed once the lock is released: %v", err)
+	}
+}
+
+func TestRewriteIsAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logFile := filepath.Join(tmpDir, "log")
+	if err := os.WriteFile(logFile, []byte("2025-01-01 : Gym : y :  : \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.UpdateHabitLog(tmpDir, civil.Date{Year: 2025, Month: 1, Day: 1}, "Gym", "n", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Rewrite should keep log permissions, got %v", info.Mode().Perm())
+	}
+
+	// No temporary files are left behind after the rename
+	files, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if strings.Contains(f.Name(), ".tmp-") {
+			t.Errorf("Temporary file left behind: %s", f.Name())
+		}
+	}
+}
+
+func TestMergeConflictedLogs(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HARSHPATH", tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte("Gym: 3/7\nRead: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	local := "2025-01-01 : Gym : y :  : \n2025-01-02 : Read : n :  : \n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "log"), []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dropbox := filepath.Join(tmpDir, "log (conflicted copy 2025-01-03)")
+	if err := os.WriteFile(dropbox, []byte("2025-01-01 : Gym : y :  : \n2025-01-02 : Read : y : finished : \n2025-01-03 : Gym : n :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	syncthing := filepath.Join(tmpDir, "log.sync-conflict-20250103-120000-ABCDEF")
+	if err := os.WriteFile(syncthing, []byte("2025-01-03 : Gym : s :  : \n2025-01-03 : Read : y :  : 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "log.bak"), []byte("2025-01-04 : Gym : y :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := storage.FindConflictedLogs(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != dropbox || paths[1] != syncthing {
+		t.Fatalf("Expected both conflicted copies and nothing else, got %v", paths)
+	}
+
+	repo, err := storage.NewFileRepository(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := repo.LoadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, warnings, err := storage.PlanMerge(*entries, filepath.Join(tmpDir, "log"), paths, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	// Identical entries are not conflicts; a day in the log and a day only in
+	// the copies each conflict once
+	if len(plan.Additions) != 2 || len(plan.Conflicts) != 2 {
+		t.Fatalf("Expected 2 additions and 2 conflicts, got %+v", plan)
+	}
+
+	var asked []storage.MergeConflict
+	added, updated, err := plan.Apply(repo, func(c storage.MergeConflict) (storage.Outcome, error) {
+		asked = append(asked, c)
+		return c.Incoming, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asked) != 2 || added != 2 || updated != 1 {
+		t.Errorf("Expected 2 conflicts asked, 2 added and 1 updated, got %d, %d, %d", len(asked), added, updated)
+	}
+
+	merged, _, err := storage.LoadLog(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := storage.Entries{
+		{Day: civil.Date{Year: 2025, Month: 1, Day: 1}, Habit: "Gym"}:  {Result: "y"},
+		{Day: civil.Date{Year: 2025, Month: 1, Day: 2}, Habit: "Read"}: {Result: "y", Comment: "finished"},
+		{Day: civil.Date{Year: 2025, Month: 1, Day: 3}, Habit: "Gym"}:  {Result: "s"},
+		{Day: civil.Date{Year: 2025, Month: 1, Day: 3}, Habit: "Read"}: {Result: "y", Amount: 2},
+	}
+	if len(*merged) != len(expected) {
+		t.Fatalf("Expected %d merged entries, got %v", len(expected), *merged)
+	}
+	for key, outcome := range expected {
+		if (*merged)[key] != outcome {
+			t.Errorf("Expected %v for %v, got %v", outcome, key, (*merged)[key])
+		}
+	}
+}
+
+func TestMergeConflictsWithArchivedEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte("Gym: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	local := "2024-12-30 : Gym : n :  : \n2024-12-31 : Gym : y :  : \n2025-01-02 : Gym : y :  :  : 07:00\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "log"), []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.ArchiveLog(tmpDir, civil.Date{Year: 2025, Month: 1, Day: 1}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	copyPath := filepath.Join(tmpDir, "log (conflicted copy 2025-01-03)")
+	conflicted := "2024-12-30 : Gym : y : caught up : \n2025-01-02 : Gym : y :  :  : 07:30\n2025-01-03 : Gym : y :  : \n"
+	if err := os.WriteFile(copyPath, []byte(conflicted), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := storage.NewFileRepository(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := repo.LoadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.LoadHistory(civil.Date{}); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, _, err := storage.PlanMerge(*entries, filepath.Join(tmpDir, "log"), []string{copyPath}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A different time of day alone is the same answer, not a conflict
+	if len(plan.Additions) != 1 || len(plan.Conflicts) != 1 {
+		t.Fatalf("Expected 1 addition and 1 conflict, got %+v", plan)
+	}
+
+	added, updated, err := plan.Apply(repo, func(c storage.MergeConflict) (storage.Outcome, error) {
+		return c.Incoming, nil
+	})
+	if err != nil {
+		t.Fatalf("Apply() with a conflict on an archived entry: %v", err)
+	}
+	if added != 2 || updated != 0 {
+		t.Errorf("Expected 2 added and 0 updated, got %d, %d", added, updated)
+	}
+
+	repo, _ = storage.NewFileRepository(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir})
+	merged, err := repo.LoadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.LoadHistory(civil.Date{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[civil.Date]storage.Outcome{
+		{Year: 2024, Month: 12, Day: 30}: {Result: "y", Comment: "caught up"},
+		{Year: 2024, Month: 12, Day: 31}: {Result: "y"},
+		{Year: 2025, Month: 1, Day: 2}:   {Result: "y", Time: "07:00"},
+		{Year: 2025, Month: 1, Day: 3}:   {Result: "y"},
+	}
+	for day, outcome := range expected {
+		if got := (*merged)[storage.DailyHabit{Day: day, Habit: "Gym"}]; got != outcome {
+			t.Errorf("Expected %v on %s, got %v", outcome, day, got)
+		}
+	}
+}
+
+func TestHabitAliases(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	habitsContent := "Gym (was: Gymmed, Went to gym): 3/7\nRead: 1\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte(habitsContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logContent := `2025-01-01 : Gymmed : y :  : 
+2025-01-02 : Went to gym : y :  : 
+2025-01-03 : Gymmed : n :  : 
+2025-01-03 : Gym : y :  : 
+2025-01-03 : Read : y :  : 
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "log"), []byte(logContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	habits, _, _, err := storage.LoadHabitsConfig(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if habits[0].Name != "Gym" || habits[0].Frequency != "3/7" || habits[0].Target != 3 {
+		t.Fatalf("Former names should not change the habit, got %+v", habits[0])
+	}
+	if len(habits[0].Aliases) != 2 || habits[0].Aliases[0] != "Gymmed" || habits[0].Aliases[1] != "Went to gym" {
+		t.Errorf("Expected former names Gymmed and Went to gym, got %v", habits[0].Aliases)
+	}
+
+	habit, err := storage.FindHabit(habits, "gymmed")
+	if err != nil || habit.Name != "Gym" {
+		t.Errorf("Expected a former name to find Gym, got %v, %v", habit, err)
+	}
+
+	entries, _, err := storage.LoadLog(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries.ResolveAliases(habits)
+
+	expected := map[civil.Date]string{
+		{Year: 2025, Month: 1, Day: 1}: "y",
+		{Year: 2025, Month: 1, Day: 2}: "y",
+		// The entry under the current name wins over a former name
+		{Year: 2025, Month: 1, Day: 3}: "y",
+	}
+	for day, result := range expected {
+		if got := (*entries)[storage.DailyHabit{Day: day, Habit: "Gym"}].Result; got != result {
+			t.Errorf("Expected Gym %s on %s, got %q", result, day, got)
+		}
+	}
+	for key := range *entries {
+		if key.Habit == "Gymmed" || key.Habit == "Went to gym" {
+			t.Errorf("Entry left under former name: %v", key)
+		}
+	}
+	if len(*entries) != 4 {
+		t.Errorf("Expected 4 entries after resolving former names, got %d", len(*entries))
+	}
+
+	// Updating through the current name rewrites the line logged under the former name
+	if err := storage.UpdateHabitLog(tmpDir, civil.Date{Year: 2025, Month: 1, Day: 1}, "Gym", "s", "", "", "", habits[0].Aliases...); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(filepath.Join(tmpDir, "log"))
+	if !strings.HasPrefix(string(content), "2025-01-01 : Gym : s :  : \n") {
+		t.Errorf("Expected the former name entry rewritten under Gym, got:\n%s", content)
+	}
+}
+
+func TestRenameHabit(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	habitsFile := filepath.Join(tmpDir, "habits")
+	logFile := filepath.Join(tmpDir, "log")
+	if err := os.WriteFile(habitsFile, []byte("! Health\nGymmed: 3/7: 2030-01-01\nRead: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logFile, []byte("# kept\n2025-01-01 : Gymmed : y : tired : 1\n2025-01-01 : Read : y :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.RenameHabit(tmpDir, tmpDir, "Gymmed", "Read", false); err == nil {
+		t.Error("Expected an error renaming to an existing habit")
+	}
+	if _, err := storage.RenameHabit(tmpDir, tmpDir, "Missing", "Other", false); err == nil {
+		t.Error("Expected an error renaming an unknown habit")
+	}
+	if _, err := storage.RenameHabit(tmpDir, tmpDir, "Gymmed", "! Gym", false); err == nil {
+		t.Error("Expected an error for a new name starting with !")
+	}
+
+	t.Run("Alias keeps the log", func(t *testing.T) {
+		changed, err := storage.RenameHabit(tmpDir, tmpDir, "Gymmed", "Gym", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != 0 {
+			t.Errorf("Expected no log entries changed, got %d", changed)
+		}
+		habits, _ := os.ReadFile(habitsFile)
+		if string(habits) != "! Health\nGym (was: Gymmed): 3/7: 2030-01-01\nRead: 1\n" {
+			t.Errorf("Unexpected habits file:\n%s", habits)
+		}
+	})
+
+	t.Run("Rename rewrites the log", func(t *testing.T) {
+		changed, err := storage.RenameHabit(tmpDir, tmpDir, "Gym", "Gymmed", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != 0 {
+			t.Errorf("Expected no entries under Gym to change, got %d", changed)
+		}
+		if _, err := storage.RenameHabit(tmpDir, tmpDir, "Gymmed", "Workout", false); err != nil {
+			t.Fatal(err)
+		}
+		habits, _ := os.ReadFile(habitsFile)
+		if string(habits) != "! Health\nWorkout: 3/7: 2030-01-01\nRead: 1\n" {
+			t.Errorf("Unexpected habits file:\n%s", habits)
+		}
+		log, _ := os.ReadFile(logFile)
+		if string(log) != "# kept\n2025-01-01 : Workout : y : tired : 1\n2025-01-01 : Read : y :  : \n" {
+			t.Errorf("Unexpected log file:\n%s", log)
+		}
+	})
+}
+
+func TestRenameHabitInIncludedFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	habitsFile := filepath.Join(tmpDir, "habits")
+	workFile := filepath.Join(tmpDir, "work.habits")
+	if err := os.WriteFile(habitsFile, []byte("Read: 1\ninclude work.habits\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(workFile, []byte("! Work\nStandup: 1 [tags=work]\nInbox zero: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "log"), []byte("2025-01-01 : Standup : y :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.RenameHabit(tmpDir, tmpDir, "Standup", "Read", false); err == nil {
+		t.Error("Expected an error renaming to a habit in the including file")
+	}
+	changed, err := storage.RenameHabit(tmpDir, tmpDir, "Standup", "Daily sync", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 1 {
+		t.Errorf("Expected 1 log entry changed, got %d", changed)
+	}
+	if habits, _ := os.ReadFile(habitsFile); string(habits) != "Read: 1\ninclude work.habits\n" {
+		t.Errorf("Including habits file should be unchanged, got:\n%s", habits)
+	}
+	if work, _ := os.ReadFile(workFile); string(work) != "! Work\nDaily sync: 1 [tags=work]\nInbox zero: 1\n" {
+		t.Errorf("Unexpected included habits file:\n%s", work)
+	}
+}
+
+func TestLogFieldEscaping(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte("Study\\: Go: 1\nRan 06:30 club: 3/7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	habits, _, _, err := storage.LoadHabitsConfig(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(habits) != 2 || habits[0].Name != "Study: Go" || habits[0].Frequency != "1" || habits[1].Name != "Ran 06:30 club" {
+		t.Fatalf("Unexpected habits: %+v %+v", habits[0], habits[1])
+	}
+
+	// Lines written before escaping existed must read back unchanged
+	old := "2025-01-01 : Read : y : woke 06:30, C:\\Users\\me, https://example.com : 2\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "log"), []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	comments := []string{
+		"06:30 run : see https://example.com/a:b",
+		"ends with a colon :",
+		": starts with one",
+		"trailing backslash \\",
+		"escape-like \\: and \\\\ and \\n text",
+		"two\nlines",
+	}
+	day := civil.Date{Year: 2025, Month: 1, Day: 2}
+	for i, comment := range comments {
+		if err := storage.WriteHabitLog(tmpDir, day.AddDays(i), "Study: Go", "y", comment, "1.5", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, warnings, err := storage.LoadLog(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	if got := (*entries)[storage.DailyHabit{Day: civil.Date{Year: 2025, Month: 1, Day: 1}, Habit: "Read"}]; got.Comment != "woke 06:30, C:\\Users\\me, https://example.com" || got.Amount != 2 {
+		t.Errorf("Old log entry read back as %+v", got)
+	}
+	for i, comment := range comments {
+		got := (*entries)[storage.DailyHabit{Day: day.AddDays(i), Habit: "Study: Go"}]
+		if got.Comment != comment || got.Amount != 1.5 {
+			t.Errorf("Comment %q read back as %+v", comment, got)
+		}
+	}
+
+	// Editing finds entries whose habit name was escaped
+	if err := storage.UpdateHabitLog(tmpDir, day, "Study: Go", "n", "12:00", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	entries, _, _ = storage.LoadLog(tmpDir)
+	if got := (*entries)[storage.DailyHabit{Day: day, Habit: "Study: Go"}]; got.Result != "n" || got.Comment != "12:00" {
+		t.Errorf("Expected edited entry, got %+v", got)
+	}
+}
+
+func TestLogTimeOfDay(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logContent := `2025-01-01 : Meds : y :  : 
+2025-01-02 : Meds : y : with breakfast : 1 : 07:10
+2025-01-03 : Meds : y :  :  : 7:5
+2025-01-04 : Meds : y :  :  : 
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "log"), []byte(logContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	day := civil.Date{Year: 2025, Month: 1, Day: 5}
+	if err := storage.WriteHabitLog(tmpDir, day, "Meds", "y", "late: 21:00", "", "21:30"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, warnings, err := storage.LoadLog(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 3 {
+		t.Errorf("Expected one warning for the invalid time on line 3, got %v", warnings)
+	}
+
+	expected := map[int]storage.Outcome{
+		1: {Result: "y"},
+		2: {Result: "y", Comment: "with breakfast", Amount: 1, Time: "07:10"},
+		3: {Result: "y"},
+		4: {Result: "y"},
+		5: {Result: "y", Comment: "late: 21:00", Time: "21:30"},
+	}
+	for d, outcome := range expected {
+		key := storage.DailyHabit{Day: civil.Date{Year: 2025, Month: 1, Day: d}, Habit: "Meds"}
+		if got := (*entries)[key]; got != outcome {
+			t.Errorf("Expected %+v on day %d, got %+v", outcome, d, got)
+		}
+	}
+
+	for _, tt := range []struct{ in, want string }{{"07:10", "07:10"}, {"7:10", "07:10"}, {" 23:59 ", "23:59"}} {
+		if got, err := storage.ParseTimeOfDay(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseTimeOfDay(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"24:00", "7", "noon", ""} {
+		if _, err := storage.ParseTimeOfDay(in); err == nil {
+			t.Errorf("Expected ParseTimeOfDay(%q) to fail", in)
+		}
+	}
+}
+
+func TestArchiveLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HARSHPATH", tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte("Gym: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logContent := `# header
+2022-12-31 : Gym : y : old : 
+2023-06-01 : Gym : n :  : 
+2023-06-01 : Gym : y : wins in log : 
+2024-03-01 : Gym : y :  : 
+not a log line
+2025-01-01 : Gym : y :  : 
+`
+	logFile := filepath.Join(tmpDir, "log")
+	if err := os.WriteFile(logFile, []byte(logContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := storage.ArchiveLog(tmpDir, civil.Date{Year: 2023, Month: 1, Day: 1}, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Moved != 1 || filepath.Base(result.Files[2022]) != "log.2022" {
+		t.Errorf("Expected 1 entry moved to log.2022, got %+v", result)
+	}
+
+	result, err = storage.ArchiveLog(tmpDir, civil.Date{Year: 2024, Month: 6, Day: 1}, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Moved != 3 || filepath.Base(result.Files[2023]) != "log.2023.gz" || filepath.Base(result.Files[2024]) != "log.2024.gz" {
+		t.Errorf("Expected 3 entries moved to gzipped archives, got %+v", result)
+	}
+
+	content, _ := os.ReadFile(logFile)
+	if string(content) != "# header\nnot a log line\n2025-01-01 : Gym : y :  : \n" {
+		t.Errorf("Unexpected log after archiving:\n%s", content)
+	}
+
+	// Appending to an existing gzipped archive adds a readable gzip member
+	if err := os.WriteFile(logFile, []byte("2024-04-01 : Gym : s :  : \n2025-01-01 : Gym : y :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.ArchiveLog(tmpDir, civil.Date{Year: 2025, Month: 1, Day: 1}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	archived, _, err := storage.LoadArchive(filepath.Join(tmpDir, "log.2024.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*archived) != 2 {
+		t.Errorf("Expected 2 entries in log.2024.gz, got %v", *archived)
+	}
+
+	repo, err := storage.NewFileRepository(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := repo.LoadHabits(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := repo.LoadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*entries) != 1 {
+		t.Errorf("Expected only the log's entry before loading history, got %v", *entries)
+	}
+
+	if _, err := repo.LoadHistory(civil.Date{Year: 2023, Month: 1, Day: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*entries) != 4 {
+		t.Errorf("Expected archives from 2023 on to be loaded, got %v", *entries)
+	}
+	if got := (*entries)[storage.DailyHabit{Day: civil.Date{Year: 2023, Month: 6, Day: 1}, Habit: "Gym"}]; got.Comment != "wins in log" {
+		t.Errorf("Expected the last archived entry for the day, got %+v", got)
+	}
+
+	if _, err := repo.LoadHistory(civil.Date{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*entries) != 5 {
+		t.Errorf("Expected the whole history to be loaded, got %v", *entries)
+	}
+}
+
+func TestLoadHistoryWithPlainAndGzippedArchives(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte("Gym: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logFile := filepath.Join(tmpDir, "log")
+	if err := os.WriteFile(logFile, []byte("2023-03-01 : Gym : y :  : \n2025-01-01 : Gym : y :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.ArchiveLog(tmpDir, civil.Date{Year: 2024, Month: 1, Day: 1}, true, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "log.2023"), []byte("2023-06-01 : Gym : n :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := storage.NewFileRepository(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := repo.LoadHabits(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := repo.LoadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.LoadHistory(civil.Date{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*entries) != 3 {
+		t.Errorf("Expected the entries of both 2023 archives, got %v", *entries)
+	}
+
+	// Later entries for the year go to the plain archive
+	if err := os.WriteFile(logFile, []byte("2023-09-01 : Gym : s :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := storage.ArchiveLog(tmpDir, civil.Date{Year: 2024, Month: 1, Day: 1}, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(result.Files[2023]) != "log.2023" {
+		t.Errorf("Expected 2023 entries appended to log.2023, got %+v", result)
+	}
+}
+
+func TestParseLoopFrequency(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"Every day", "1", false},
+		{"daily", "1", false},
+		{"3 times per week", "3/7", false},
+		{"Once a week", "7", false},
+		{"twice per month", "2/30", false},
+		{"Every 2 days", "2", false},
+		{"5 times per 2 weeks", "5/14", false},
+		{"often", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := storage.ParseLoopFrequency(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseLoopFrequency(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportLoop(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HARSHPATH", tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte("Gym: 3/7"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "log"), []byte("2025-01-01 : Gym : n :  : \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	export := t.TempDir()
+	files := map[string]string{
+		"Habits.csv": "Position,Name,Type,Question,Description,FrequencyNumerator,FrequencyDenominator,Color,Unit,Target Type,Target Value,Archived?\n" +
+			"001,Meditate,0,,,1,1,#FF0000,,0,0,false\n" +
+			"002,Workout,0,,,3,7,#00FF00,,0,0,false\n" +
+			"003,Run,1,,,1,7,#0000FF,km,0,0,true\n",
+		"001 Meditate/Checkmarks.csv": "2025-01-01,2\n2025-01-02,0\n2025-01-03,-1\n2025-01-04,3\n",
+		"002 Workout/Checkmarks.csv":  "2025-01-01,2\n2025-01-02,1\n",
+		"003 Run/Checkmarks.csv":      "2024-06-01,5500\n2024-06-08,0\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(export, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(export, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	imported, err := storage.ReadLoopExport(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 3 {
+		t.Fatalf("Expected 3 imported habits, got %d", len(imported))
+	}
+	if imported[1].Habit.Frequency != "3/7" || imported[0].Habit.Frequency != "1" || imported[2].Habit.Frequency != "7" {
+		t.Errorf("Unexpected frequencies: %q, %q, %q", imported[0].Habit.Frequency, imported[1].Habit.Frequency, imported[2].Habit.Frequency)
+	}
+	if len(imported[0].Entries) != 3 {
+		t.Errorf("Expected unknown days to be left out, got %v", imported[0].Entries)
+	}
+	if imported[2].Habit.EndRecord != (civil.Date{Year: 2024, Month: 6, Day: 8}) {
+		t.Errorf("Expected archived habit to end on its last day, got %v", imported[2].Habit.EndRecord)
+	}
+
+	repo, err := storage.NewFileRepository(storage.Paths{ConfigDir: tmpDir, LogDir: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	habits, _, err := repo.LoadHabits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := repo.LoadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.PlanImport(imported, storage.ImportOptions{Skip: []string{"Swim"}}, habits, *entries); err == nil {
+		t.Error("Expected skipping an unknown imported habit to fail")
+	}
+
+	opts := storage.ImportOptions{Names: map[string]string{"Workout": "gym"}, Skip: []string{"Meditate"}}
+	plan, err := storage.PlanImport(imported, opts, habits, *entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.NewHabits) != 1 || plan.Matched["Workout"] != "Gym" || plan.Existing != 1 || len(plan.Additions) != 3 {
+		t.Fatalf("Unexpected import plan: %+v", plan)
+	}
+
+	added, err := plan.Apply(repo, "Loop Habit Tracker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 3 {
+		t.Errorf("Expected 3 entries added, got %d", added)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(tmpDir, "habits"))
+	if string(content) != "Gym: 3/7\n\n! Loop Habit Tracker\nRun: 7: 2024-06-08\n" {
+		t.Errorf("Unexpected habits file after import:\n%s", content)
+	}
+	content, _ = os.ReadFile(filepath.Join(tmpDir, "log"))
+	want := "2025-01-01 : Gym : n :  : \n2024-06-01 : Run : y :  : 5.5\n2024-06-08 : Run : n :  : \n2025-01-02 : Gym : n :  : \n"
+	if string(content) != want {
+		t.Errorf("Unexpected log after import:\n%s", content)
+	}
+}
+
+func TestReadOrgFile(t *testing.T) {
+	orgFile := filepath.Join(t.TempDir(), "habits.org")
+	content := `#+TITLE: Habits
+* Health
+** TODO [#A] Go running                                             :sport:
+   SCHEDULED: <2025-01-10 Fri .+2d/4d>
+   :PROPERTIES:
+   :STYLE:    habit
+   :LAST_REPEAT: [2025-01-08 Wed 18:02]
+   :END:
+   :LOGBOOK:
+   - State "DONE"       from "TODO"       [2025-01-08 Wed 18:02] \\
+     Felt great
+   - State "DONE"       from "TODO"       [2025-01-08 Wed 07:00]
+   - State "DONE"       from "TODO"       [2025-01-05 Sun 09:15]
+   :END:
+** TODO Stretch
+   SCHEDULED: <2025-01-09 Thu ++1w>
+   - State "DONE"       from "TODO"       [2025-01-02 Thu 21:00]
+* Projects
+** TODO Write report
+   DEADLINE: <2025-02-01 Sat>
+** DONE Floss
+   CLOSED: [2024-12-31 Tue 22:00]
+   :PROPERTIES:
+   :STYLE:    habit
+   :END:
+`
+	if err := os.WriteFile(orgFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := storage.ReadOrgFile(orgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 3 {
+		t.Fatalf("Expected 3 habits and no plain TODOs, got %d", len(imported))
+	}
+
+	running := imported[0]
+	if running.Habit.Name != "Go running" || running.Habit.Heading != "Health" || running.Habit.Frequency != "4" {
+		t.Errorf("Unexpected habit %+v", running.Habit)
+	}
+	if len(running.Entries) != 2 {
+		t.Errorf("Expected one entry per day, got %v", running.Entries)
+	}
+	want := storage.Outcome{Result: "y", Comment: "Felt great", Time: "18:02"}
+	if got := running.Entries[civil.Date{Year: 2025, Month: 1, Day: 8}]; got != want {
+		t.Errorf("Expected the newest change with its note, got %+v", got)
+	}
+	if imported[1].Habit.Frequency != "7" || len(imported[1].Entries) != 1 {
+		t.Errorf("Expected a weekly habit with state changes outside a drawer, got %+v", imported[1])
+	}
+	if imported[2].Habit.Heading != "Projects" || imported[2].Habit.EndRecord != (civil.Date{Year: 2024, Month: 12, Day: 31}) {
+		t.Errorf("Expected a closed habit to end on its closing date, got %+v", imported[2].Habit)
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `# harsh settings
+color: never
+graph-days: 60
+symbols: braille
+week-start Sunday
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "config"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HARSH_GRAPH_DAYS", "30")
+
+	settings, warnings, err := storage.LoadSettings(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("Expected warnings for the invalid value and malformed line, got %v", warnings)
+	}
+	if settings.Color != "never" || settings.Source("color") != "config" {
+		t.Errorf("Expected color from the settings file, got %q from %s", settings.Color, settings.Source("color"))
+	}
+	if settings.GraphDays != 30 || settings.Source("graph-days") != "HARSH_GRAPH_DAYS" {
+		t.Errorf("Expected the environment to override the settings file, got %d from %s", settings.GraphDays, settings.Source("graph-days"))
+	}
+	if settings.Symbols != "unicode" || settings.WeekStart != "monday" || settings.TodoDays != 8 {
+		t.Errorf("Expected defaults for skipped settings, got %+v", settings)
+	}
+
+	t.Setenv("HARSH_HIDE_ENDED", "sometimes")
+	if _, _, err := storage.LoadSettings(tmpDir); err == nil {
+		t.Error("Expected an invalid environment value to be an error")
+	}
+}
+
+func TestSetSetting(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, "config")
+	if err := os.WriteFile(settingsPath, []byte("# Mine\ncolor: auto\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.SetSetting(tmpDir, "color", "Never"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SetSetting(tmpDir, "hide-ended", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SetSetting(tmpDir, "todo-days", "soon"); err == nil {
+		t.Error("Expected an invalid value to be refused")
+	}
+	if err := storage.SetSetting(tmpDir, "todo-days", "0"); err == nil {
+		t.Error("Expected todo-days below 1 to be refused")
+	}
+	if err := storage.SetSetting(tmpDir, "colour", "never"); err == nil {
+		t.Error("Expected an unknown key to be refused")
+	}
+
+	content, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Mine\ncolor: never\nhide-ended: true\n"; string(content) != want {
+		t.Errorf("Expected settings file %q, got %q", want, string(content))
+	}
+}
+
+func TestFindPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("HARSHPATH", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "relative/data")
+
+	paths, err := storage.FindPaths("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultDir := filepath.Join(home, ".config", "harsh")
+	if paths.ConfigDir != defaultDir || paths.LogDir != defaultDir {
+		t.Errorf("Expected both files in %s, got %+v", defaultDir, paths)
+	}
+
+	configHome := filepath.Join(home, "xdg-config")
+	dataHome := filepath.Join(home, "xdg-data")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	paths, err = storage.FindPaths("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	workDir := filepath.Join(configHome, "harsh", "profiles", "work")
+	if paths.ConfigDir != workDir || paths.LogDir != workDir {
+		t.Errorf("Expected the work profile in %s without a log in the data dir, got %+v", workDir, paths)
+	}
+
+	dataDir := filepath.Join(dataHome, "harsh", "profiles", "work")
+	if err := storage.CreateNewLogFile(dataDir); err != nil {
+		t.Fatal(err)
+	}
+	paths, err = storage.FindPaths("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths.ConfigDir != workDir || paths.LogDir != dataDir {
+		t.Errorf("Expected the log kept in the data dir to be used, got %+v", paths)
+	}
+	if profiles := storage.Profiles(); len(profiles) != 0 {
+		t.Errorf("Expected no profiles before the habits file is created, got %v", profiles)
+	}
+
+	t.Setenv("HARSHPATH", filepath.Join(home, "harsh"))
+	paths, err = storage.FindPaths("personal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	personalDir := filepath.Join(home, "harsh", "profiles", "personal")
+	if paths.ConfigDir != personalDir || paths.LogDir != personalDir {
+		t.Errorf("Expected HARSHPATH to hold the whole profile, got %+v", paths)
+	}
+
+	for _, name := range []string{"../work", "a/b", ".hidden"} {
+		if _, err := storage.FindPaths(name); err == nil {
+			t.Errorf("Expected profile name %q to be refused", name)
+		}
+	}
+}
+
+func TestLoadHabitsInclude(t *testing.T) {
+	tmpDir := t.TempDir()
+	sharedDir := filepath.Join(tmpDir, "team")
+	if err := os.MkdirAll(sharedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"habits":         "! Personal\nRead: 1\ninclude team/*.habits\nWrite: 3/7\n",
+		"team/a.habits":  "Standup: 1\n! Team\nReview: 2/7\nRead: 1\n",
+		"team/b.habits":  "Retro: 14\n",
+		"team/notes.txt": "not habits\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	habits, _, warnings, err := storage.LoadHabitsConfig(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, habit := range habits {
+		got = append(got, habit.Heading+"/"+habit.Name)
+	}
+	want := []string{"Personal/Read", "Personal/Standup", "Team/Review", "Personal/Retro", "Personal/Write"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected habits %v, got %v", want, got)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 4 || !strings.Contains(warnings[0].Reason, filepath.Join(tmpDir, "habits")+":2") {
+		t.Errorf("Expected the duplicate Read reported against its first definition, got %v", warnings)
+	}
+
+	// A file including itself through another is a cycle
+	if err := os.WriteFile(filepath.Join(tmpDir, "team/b.habits"), []byte("include habits\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = storage.LoadHabitsConfig(tmpDir)
+	var parseErr *storage.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 1 || !strings.Contains(parseErr.Reason, "include cycle") {
+		t.Errorf("Expected an include cycle error at b.habits:1, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte("Read: 1\ninclude missing.habits\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = storage.LoadHabitsConfig(tmpDir)
+	if !errors.As(err, &parseErr) || parseErr.Line != 2 || !strings.Contains(parseErr.Reason, "not found") {
+		t.Errorf("Expected a missing include error at habits:2, got %v", err)
+	}
+}
+
+func TestHabitAttributes(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `Run: 3/7 [unit=km; tags=health, cardio; desc="Zone 2; \"easy\" only"]
+Water: 1: 2030-01-01 [unit=glasses; colour=blue]
+Read: 1 [tags=mind
+Plain: 1
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	habits, _, warnings, err := storage.LoadHabitsConfig(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(habits) != 4 {
+		t.Fatalf("Expected habits with bad attributes to still load, got %d", len(habits))
+	}
+	if len(warnings) != 2 || warnings[0].Line != 2 || warnings[1].Line != 3 {
+		t.Errorf("Expected warnings for the unknown attribute and missing ], got %v", warnings)
+	}
+
+	run := habits[0]
+	if run.Frequency != "3/7" || run.Unit != "km" || run.Description != `Zone 2; "easy" only` {
+		t.Errorf("Unexpected attributes %+v", run)
+	}
+	if len(run.Tags) != 2 || !run.HasTag("Cardio") || run.HasTag("mind") {
+		t.Errorf("Expected tags health and cardio, got %v", run.Tags)
+	}
+	if habits[1].Unit != "glasses" || habits[1].EndRecord != (civil.Date{Year: 2030, Month: 1, Day: 1}) {
+		t.Errorf("Expected attributes after an end date, got %+v", habits[1])
+	}
+	if habits[2].Frequency != "1" || len(habits[2].Tags) != 0 {
+		t.Errorf("Expected a malformed block to be dropped, got %+v", habits[2])
+	}
+
+	want := `Run: 3/7 [unit=km; tags=health,cardio; desc="Zone 2; \"easy\" only"]`
+	if got := storage.FormatHabit(run); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if got := storage.FilterHabitsByTag(habits, []string{"HEALTH", "other"}); len(got) != 1 || got[0] != run {
+		t.Errorf("Expected only Run tagged health, got %v", got)
+	}
+}
+
+func TestHabitAmountFrequency(t *testing.T) {
+	tests := []struct {
+		frequency string
+		interval  int
+		amount    float64
+		unit      string
+		shouldErr bool
+	}{
+		{"8 glasses/1", 1, 8, "glasses", false},
+		{"20km/7", 7, 20, "km", false},
+		{"2.5 l/1w", 7, 2.5, "l", false},
+		{"0 glasses/1", 0, 0, "", true},
+		{"8 glasses/0", 0, 0, "", true},
+		{"8 glasses", 0, 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.frequency, func(t *testing.T) {
+			h := &storage.Habit{Name: "Test", Frequency: tt.frequency}
+			err := h.ParseHabitFrequency()
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error for frequency %q", tt.frequency)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for frequency %q: %v", tt.frequency, err)
+			}
+			if h.Target != 1 || h.Interval != tt.interval || h.Amount != tt.amount || h.Unit != tt.unit {
+				t.Errorf("got target=%d interval=%d amount=%v unit=%q, want target=1 interval=%d amount=%v unit=%q",
+					h.Target, h.Interval, h.Amount, h.Unit, tt.interval, tt.amount, tt.unit)
+			}
+		})
+	}
+
+	// A unit attribute takes precedence, and the frequency's own unit is not
+	// written out again
+	h := &storage.Habit{Name: "Run", Frequency: "20km/7", Unit: "miles"}
+	if err := h.ParseHabitFrequency(); err != nil {
+		t.Fatal(err)
+	}
+	if h.Unit != "miles" {
+		t.Errorf("Unit = %q, want miles", h.Unit)
+	}
+	h = &storage.Habit{Name: "Water", Frequency: "8 glasses/1"}
+	if err := h.ParseHabitFrequency(); err != nil {
+		t.Fatal(err)
+	}
+	if got := storage.FormatHabit(h); got != "Water: 8 glasses/1" {
+		t.Errorf("FormatHabit() = %q, want %q", got, "Water: 8 glasses/1")
+	}
+}
+
+func TestHabitAvoidFrequency(t *testing.T) {
+	tests := []struct {
+		frequency string
+		limit     int
+		interval  int
+		shouldErr bool
+	}{
+		{"!", 0, 1, false},
+		{"!0", 0, 1, false},
+		{"!1", 1, 1, false},
+		{"!7", 1, 7, false},
+		{"!2/7", 2, 7, false},
+		{"! 2/1w", 2, 7, false},
+		{"-1/7", 0, 0, true},
+		{"!8/7", 0, 0, true},
+		{"!20km/7", 0, 0, true},
+		{"!!", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.frequency, func(t *testing.T) {
+			h := &storage.Habit{Name: "Test", Frequency: tt.frequency}
+			err := h.ParseHabitFrequency()
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error for frequency %q", tt.frequency)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for frequency %q: %v", tt.frequency, err)
+			}
+			if !h.Avoid || h.Target != 1 || h.Limit != tt.limit || h.Interval != tt.interval {
+				t.Errorf("got avoid=%v target=%d limit=%d interval=%d, want avoid=true target=1 limit=%d interval=%d",
+					h.Avoid, h.Target, h.Limit, h.Interval, tt.limit, tt.interval)
+			}
+		})
+	}
+}
+
+func TestHabitWeekdayFrequency(t *testing.T) {
+	tests := []struct {
+		frequency string
+		weekdays  []time.Weekday
+		shouldErr bool
+	}{
+		{"mon,wed,fri", []time.Weekday{time.Monday, time.Wednesday, time.Friday}, false},
+		{"tue", []time.Weekday{time.Tuesday}, false},
+		{"Saturday, sun", []time.Weekday{time.Saturday, time.Sunday}, false},
+		{"mon,mon", []time.Weekday{time.Monday}, false},
+		{"mo,we", nil, true},
+		{"mon,funday", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.frequency, func(t *testing.T) {
+			h := &storage.Habit{Name: "Test", Frequency: tt.frequency}
+			err := h.ParseHabitFrequency()
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error for frequency %q", tt.frequency)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for frequency %q: %v", tt.frequency, err)
+			}
+			if h.Target != 1 || h.Interval != 1 || !slices.Equal(h.Weekdays, tt.weekdays) {
+				t.Errorf("got target=%d interval=%d weekdays=%v, want target=1 interval=1 weekdays=%v",
+					h.Target, h.Interval, h.Weekdays, tt.weekdays)
+			}
+		})
+	}
+
+	monday := civil.Date{Year: 2025, Month: 7, Day: 7}
+	h := &storage.Habit{Name: "Trash out", Frequency: "tue"}
+	if err := h.ParseHabitFrequency(); err != nil {
+		t.Fatal(err)
+	}
+	if h.IsScheduled(monday) || !h.IsScheduled(monday.AddDays(1)) {
+		t.Error("IsScheduled() should only be true on Tuesdays")
+	}
+}
+
+func TestHabitCalendarPeriodFrequency(t *testing.T) {
+	tests := []struct {
+		frequency string
+		target    int
+		interval  int
+		period    string
+		shouldErr bool
+	}{
+		{"2/month", 2, 30, "month", false},
+		{"1/Week", 1, 7, "week", false},
+		{"1 / quarter", 1, 91, "quarter", false},
+		{"3/7", 3, 7, "", false},
+		{"31/month", 0, 0, "", true},
+		{"!1/week", 0, 0, "", true},
+		{"20km/month", 0, 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.frequency, func(t *testing.T) {
+			h := &storage.Habit{Name: "Test", Frequency: tt.frequency}
+			err := h.ParseHabitFrequency()
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error for frequency %q", tt.frequency)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for frequency %q: %v", tt.frequency, err)
+			}
+			if h.Target != tt.target || h.Interval != tt.interval || h.Period != tt.period {
+				t.Errorf("got target=%d interval=%d period=%q, want target=%d interval=%d period=%q",
+					h.Target, h.Interval, h.Period, tt.target, tt.interval, tt.period)
+			}
+		})
+	}
+}
+
+func TestLoadHabitsConfigWithStartDate(t *testing.T) {
+	tmpDir := t.TempDir()
+	habitsFile := filepath.Join(tmpDir, "habits")
+	content := "Marathon training: 4/7: 2025-05-01..2025-10-20\nStretches: 1: 2025-06-01..\nOld habit: 1: 2024-06-15\n"
+	if err := os.WriteFile(habitsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	habits, _, _, err := storage.LoadHabitsConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadHabitsConfig() error: %v", err)
+	}
+	want := []struct{ start, end civil.Date }{
+		{civil.Date{Year: 2025, Month: 5, Day: 1}, civil.Date{Year: 2025, Month: 10, Day: 20}},
+		{civil.Date{Year: 2025, Month: 6, Day: 1}, civil.Date{}},
+		{civil.Date{}, civil.Date{Year: 2024, Month: 6, Day: 15}},
+	}
+	for i, w := range want {
+		if habits[i].StartRecord != w.start || habits[i].EndRecord != w.end {
+			t.Errorf("%s: got start=%v end=%v, want start=%v end=%v", habits[i].Name, habits[i].StartRecord, habits[i].EndRecord, w.start, w.end)
+		}
+		if got := storage.FormatHabit(habits[i]); got != strings.Split(content, "\n")[i] {
+			t.Errorf("FormatHabit() = %q, want %q", got, strings.Split(content, "\n")[i])
+		}
+	}
+
+	if habits[1].HasStarted(civil.Date{Year: 2025, Month: 5, Day: 31}) {
+		t.Error("Stretches should not have started on 2025-05-31")
+	}
+	if !habits[1].HasStarted(civil.Date{Year: 2025, Month: 6, Day: 1}) {
+		t.Error("Stretches should have started on 2025-06-01")
+	}
+
+	// An explicit start date overrides the first entry as the first record
+	entries := storage.Entries{
+		storage.DailyHabit{Day: civil.Date{Year: 2025, Month: 5, Day: 20}, Habit: "Stretches"}: {Result: "y"},
+	}
+	entries.FirstRecords(civil.Date{Year: 2025, Month: 1, Day: 1}, civil.Date{Year: 2025, Month: 7, Day: 1}, habits)
+	if habits[1].FirstRecord != habits[1].StartRecord {
+		t.Errorf("FirstRecord = %v, want the start date %v", habits[1].FirstRecord, habits[1].StartRecord)
+	}
+
+	for _, bad := range []string{"Bad: 1: 2025-13-01..", "Bad: 1: 2025-06-01..2025-05-01", "Bad: 1: 2025-06-01..soon"} {
+		if err := os.WriteFile(habitsFile, []byte(bad+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, _, _, err := storage.LoadHabitsConfig(tmpDir)
+		var parseErr *storage.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected *storage.ParseError, got %v", bad, err)
+		}
+	}
+}
+
+func TestLoadHabitsPauses(t *testing.T) {
+	tmpDir := t.TempDir()
+	habitsFile := filepath.Join(tmpDir, "habits")
+	content := `# Habits
+pause 2025-07-01..2025-07-14
+! Health
+Gym: 3/7 [pause=2025-03-01..2025-03-02,2025-04-10]
+! Work
+Emails: 1
+pause 2025-12-24..2025-12-26
+pause 2025-13-01
+`
+	if err := os.WriteFile(habitsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	habits, _, warnings, err := storage.LoadHabitsConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadHabitsConfig() error: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 8 {
+		t.Errorf("Expected a warning for the invalid pause on line 8, got %v", warnings)
+	}
+
+	day := func(s string) civil.Date { d, _ := civil.ParseDate(s); return d }
+	tests := []struct {
+		habit    int
+		d        civil.Date
+		expected bool
+	}{
+		{0, day("2025-07-01"), true},  // paused for all habits
+		{1, day("2025-07-14"), true},  // paused for all habits
+		{1, day("2025-07-15"), false}, // after the pause
+		{0, day("2025-03-02"), true},  // Gym's own pause
+		{0, day("2025-04-10"), true},  // Gym's own single day
+		{1, day("2025-03-02"), false}, // Gym's pause is its own
+		{1, day("2025-12-25"), true},  // Work heading pause
+		{0, day("2025-12-25"), false}, // not under the Work heading
+	}
+	for _, tt := range tests {
+		if got := habits[tt.habit].IsPaused(tt.d); got != tt.expected {
+			t.Errorf("%s.IsPaused(%s) = %v, want %v", habits[tt.habit].Name, tt.d, got, tt.expected)
+		}
+	}
+	if got, want := storage.FormatHabit(habits[0]), "Gym: 3/7 [pause=2025-03-01..2025-03-02,2025-04-10]"; got != want {
+		t.Errorf("FormatHabit() = %q, want %q", got, want)
+	}
+
+	// Adding pauses to habits and for all habits
+	content = "# Habits\n! Health\nGym: 3/7 [tags=health]\nRead: 1\n"
+	if err := os.WriteFile(habitsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pause, err := storage.ParsePause("2025-07-01..2025-07-14")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.AddPause(tmpDir, pause, []string{"Gym", "Read"}); err != nil {
+		t.Fatalf("AddPause() error: %v", err)
+	}
+	if err := storage.AddPause(tmpDir, storage.Pause{From: day("2025-08-01"), To: day("2025-08-01")}, []string{"Gym"}); err != nil {
+		t.Fatalf("AddPause() error: %v", err)
+	}
+	if err := storage.AddPause(tmpDir, pause, nil); err != nil {
+		t.Fatalf("AddPause() error: %v", err)
+	}
+	got, _ := os.ReadFile(habitsFile)
+	want := "# Habits\npause 2025-07-01..2025-07-14\n! Health\nGym: 3/7 [tags=health; pause=2025-07-01..2025-07-14,2025-08-01]\nRead: 1 [pause=2025-07-01..2025-07-14]\n"
+	if string(got) != want {
+		t.Errorf("habits file after AddPause:\n%s\nwant:\n%s", got, want)
+	}
+	if err := storage.AddPause(tmpDir, pause, []string{"Nope"}); err == nil {
+		t.Error("AddPause() should fail for a habit not in the habits file")
+	}
+
+	for _, bad := range []string{"2025-07-14..2025-07-01", "2025-07-01..", "July"} {
+		if _, err := storage.ParsePause(bad); err == nil {
+			t.Errorf("ParsePause(%q) should fail", bad)
+		}
+	}
+}
+
+func TestAddPauseInIncludedFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	habitsFile := filepath.Join(tmpDir, "habits")
+	workFile := filepath.Join(tmpDir, "work.habits")
+	if err := os.WriteFile(habitsFile, []byte("Read: 1\ninclude work.habits\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(workFile, []byte("! Work\nStandup: 1 [tags=work]\nInbox zero: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pause, err := storage.ParsePause("2025-07-01..2025-07-14")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.AddPause(tmpDir, pause, []string{"Standup", "Read"}); err != nil {
+		t.Fatalf("AddPause() error: %v", err)
+	}
+	if habits, _ := os.ReadFile(habitsFile); string(habits) != "Read: 1 [pause=2025-07-01..2025-07-14]\ninclude work.habits\n" {
+		t.Errorf("Unexpected habits file:\n%s", habits)
+	}
+	if work, _ := os.ReadFile(workFile); string(work) != "! Work\nStandup: 1 [tags=work; pause=2025-07-01..2025-07-14]\nInbox zero: 1\n" {
+		t.Errorf("Unexpected included habits file:\n%s", work)
+	}
+
+	habits, _, _, err := storage.LoadHabitsConfig(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, habit := range habits {
+		if paused := habit.IsPaused(pause.From); paused != (habit.Name != "Inbox zero") {
+			t.Errorf("%s.IsPaused(%s) = %v", habit.Name, pause.From, paused)
+		}
+	}
+}
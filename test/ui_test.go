@@ -3,8 +3,10 @@ package test
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/civil"
 	"github.com/wakatara/harsh/internal/storage"
@@ -172,6 +174,188 @@ func TestUIBuildStats(t *testing.T) {
 	}
 }
 
+func TestUIBuildTimeStats(t *testing.T) {
+	start := civil.Date{Year: 2025, Month: 1, Day: 1}
+	habit := &storage.Habit{Name: "Bed", Target: 1, Interval: 1, FirstRecord: start}
+
+	// Bedtimes either side of midnight, drifting 40 minutes later in the last week
+	times := []string{"23:50", "00:10", "23:40", "00:00", "23:55", "00:05", "23:45", "00:15",
+		"00:30", "00:40", "00:35", "00:45", "00:40", "00:30", "00:50"}
+	entries := &storage.Entries{}
+	for i, tm := range times {
+		(*entries)[storage.DailyHabit{Day: start.AddDays(i), Habit: "Bed"}] = storage.Outcome{Result: "y", Time: tm}
+	}
+	// Untimed entries and breaks are not averaged
+	(*entries)[storage.DailyHabit{Day: start.AddDays(len(times)), Habit: "Bed"}] = storage.Outcome{Result: "y"}
+	(*entries)[storage.DailyHabit{Day: start.AddDays(len(times) + 1), Habit: "Bed"}] = storage.Outcome{Result: "n", Time: "12:00"}
+
+	stats := ui.BuildTimeStats(habit, entries)
+	if stats.Count != len(times) {
+		t.Errorf("Expected %d timed entries, got %d", len(times), stats.Count)
+	}
+	if stats.Usual != "00:17" {
+		t.Errorf("Expected usual time just after midnight 00:17, got %s", stats.Usual)
+	}
+	if stats.Recent != "00:39" {
+		t.Errorf("Expected recent time 00:39, got %s", stats.Recent)
+	}
+	if stats.Drift < 35 || stats.Drift > 45 {
+		t.Errorf("Expected a drift of about 40 minutes later, got %d", stats.Drift)
+	}
+
+	if stats := ui.BuildTimeStats(&storage.Habit{Name: "Unlogged"}, entries); stats.Count != 0 || stats.Usual != "" {
+		t.Errorf("Expected no time stats for a habit without entries, got %+v", stats)
+	}
+}
+
+func TestUIExportTable(t *testing.T) {
+	start := civil.Date{Year: 2025, Month: 1, Day: 1}
+	habits := []*storage.Habit{
+		{Name: "Gym", Heading: "Health", Frequency: "2/3", Target: 2, Interval: 3, FirstRecord: start},
+		{Name: "Read, daily", Target: 1, Interval: 1, FirstRecord: start.AddDays(1), EndRecord: start.AddDays(2)},
+	}
+	entries := &storage.Entries{
+		{Day: start, Habit: "Gym"}:                    {Result: "y", Amount: 1.5, Time: "07:30"},
+		{Day: start.AddDays(1), Habit: "Gym"}:         {Result: "y"},
+		{Day: start.AddDays(2), Habit: "Gym"}:         {Result: "n", Comment: "rest"},
+		{Day: start.AddDays(1), Habit: "Read, daily"}: {Result: "s"},
+		{Day: start.AddDays(3), Habit: "Read, daily"}: {Result: "y"},
+	}
+
+	var buf bytes.Buffer
+	if err := ui.ExportTable(&buf, ',', habits, entries, civil.Date{}, start.AddDays(3), "", false); err != nil {
+		t.Fatal(err)
+	}
+	want := `date,habit,heading,result,status,amount,comment,time
+2025-01-01,Gym,Health,y,done,1.5,,07:30
+2025-01-02,Gym,Health,y,done,,,
+2025-01-02,"Read, daily",,s,skip,,,
+2025-01-03,Gym,Health,n,satisfied,,rest,
+2025-01-03,"Read, daily",,,unrecorded,,,
+2025-01-04,Gym,Health,,unrecorded,,,
+`
+	if buf.String() != want {
+		t.Errorf("Unexpected CSV export:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ui.ExportTable(&buf, '\t', habits, entries, start.AddDays(2), start.AddDays(2), "gym", false); err != nil {
+		t.Fatal(err)
+	}
+	want = "date\thabit\theading\tresult\tstatus\tamount\tcomment\ttime\n2025-01-03\tGym\tHealth\tn\tsatisfied\t\trest\t\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected filtered TSV export:\n%q", buf.String())
+	}
+}
+
+func TestUIExportICS(t *testing.T) {
+	today := civil.DateOf(time.Now())
+	habits := []*storage.Habit{
+		{Name: "Read", Frequency: "1", Target: 1, Interval: 1, FirstRecord: today.AddDays(-3)},
+		{Name: "Gym", Heading: "Health", Frequency: "3/7", Target: 3, Interval: 7, FirstRecord: today.AddDays(-20)},
+		{Name: "Coffee", Frequency: "0", Target: 0, Interval: 1, FirstRecord: today.AddDays(-3)},
+		{Name: "A habit with a long name, which is folded; onto a continuation line", Frequency: "7", Target: 1, Interval: 7},
+	}
+	entries := &storage.Entries{
+		{Day: today, Habit: "Read"}:               {Result: "y"},
+		{Day: today.AddDays(-20), Habit: "Gym"}:   {Result: "y"},
+		{Day: today.AddDays(-3), Habit: "Coffee"}: {Result: "y"},
+	}
+	date := func(d civil.Date) string {
+		return strings.ReplaceAll(d.String(), "-", "")
+	}
+
+	var buf bytes.Buffer
+	if err := ui.ExportICS(&buf, habits, entries, "", false, false, "07:30"); err != nil {
+		t.Fatal(err)
+	}
+	ics := buf.String()
+	if strings.Count(ics, "BEGIN:VEVENT") != 3 || strings.Contains(ics, "Coffee") {
+		t.Errorf("Expected an event for each habit with a streak, got:\n%s", ics)
+	}
+	if !strings.Contains(ics, "DTSTART;VALUE=DATE:"+date(today.AddDays(1))+"\r\nDTEND;VALUE=DATE:"+date(today.AddDays(2))) {
+		t.Errorf("Expected Read, done today, to be due tomorrow:\n%s", ics)
+	}
+	if !strings.Contains(ics, "DTSTART;VALUE=DATE:"+date(today)+"\r\n") {
+		t.Errorf("Expected the broken Gym streak to be due today:\n%s", ics)
+	}
+	if strings.Count(ics, "TRIGGER;RELATED=START:PT7H30M") != 3 || !strings.Contains(ics, "CATEGORIES:Health") {
+		t.Errorf("Expected alarms and categories:\n%s", ics)
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Expected lines folded to 75 octets, got %q", line)
+		}
+	}
+	if !strings.Contains(strings.ReplaceAll(ics, "\r\n ", ""), `SUMMARY:A habit with a long name\, which is folded\; onto a continuation line (7)`) {
+		t.Errorf("Expected escaped summary to unfold, got:\n%s", ics)
+	}
+
+	buf.Reset()
+	if err := ui.ExportICS(&buf, habits, entries, "read", false, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	ics = buf.String()
+	if strings.Count(ics, "BEGIN:VTODO") != 1 || !strings.Contains(ics, "DUE;VALUE=DATE:") || strings.Contains(ics, "VALARM") {
+		t.Errorf("Expected one to-do without an alarm, got:\n%s", ics)
+	}
+}
+
+func TestUIExportOrgRoundTrip(t *testing.T) {
+	start := civil.Date{Year: 2025, Month: 1, Day: 1}
+	habits := []*storage.Habit{
+		{Name: "Gym", Heading: "Health", Frequency: "3/7", Target: 3, Interval: 7, FirstRecord: start, StartRecord: start},
+		{Name: "Coffee", Heading: "Health", Frequency: "0", Target: 0, Interval: 1, FirstRecord: start},
+		{Name: "Floss", Heading: "Retired", Frequency: "1", Target: 1, Interval: 1, FirstRecord: start, EndRecord: start.AddDays(1)},
+	}
+	entries := &storage.Entries{
+		{Day: start, Habit: "Gym"}:            {Result: "y", Comment: "legs\nand back", Amount: 2.5, Time: "07:30"},
+		{Day: start.AddDays(1), Habit: "Gym"}: {Result: "n"},
+		{Day: start.AddDays(2), Habit: "Gym"}: {Result: "s", Comment: "sick"},
+		{Day: start, Habit: "Coffee"}:         {Result: "y", Amount: 3},
+		{Day: start, Habit: "Floss"}:          {Result: "y"},
+	}
+
+	var buf bytes.Buffer
+	if err := ui.ExportOrg(&buf, habits, entries, "", false); err != nil {
+		t.Fatal(err)
+	}
+	org := buf.String()
+	if !strings.Contains(org, "* Health\n** TODO Gym\nSCHEDULED: <") || !strings.Contains(org, " .+2d/3d>") {
+		t.Errorf("Expected Gym scheduled with a repeater under its heading, got:\n%s", org)
+	}
+	if !strings.Contains(org, "** DONE Floss\nCLOSED: [2025-01-02 Thu]") {
+		t.Errorf("Expected the ended habit closed on its end date, got:\n%s", org)
+	}
+
+	orgFile := filepath.Join(t.TempDir(), "habits.org")
+	if err := os.WriteFile(orgFile, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := storage.ReadOrgFile(orgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != len(habits) {
+		t.Fatalf("Expected %d habits back, got %d", len(habits), len(imported))
+	}
+	for i, ih := range imported {
+		h := habits[i]
+		if ih.Habit.Name != h.Name || ih.Habit.Heading != h.Heading || ih.Habit.Frequency != h.Frequency ||
+			ih.Habit.StartRecord != h.StartRecord || ih.Habit.EndRecord != h.EndRecord {
+			t.Errorf("Habit %s did not round trip: %+v", h.Name, ih.Habit)
+		}
+		for day, outcome := range ih.Entries {
+			if want := (*entries)[storage.DailyHabit{Day: day, Habit: h.Name}]; outcome != want {
+				t.Errorf("Entry %s %s did not round trip: got %+v, want %+v", day, h.Name, outcome, want)
+			}
+		}
+	}
+	if len(imported[0].Entries) != 3 {
+		t.Errorf("Expected all of Gym's entries back, got %v", imported[0].Entries)
+	}
+}
+
 func TestDisplayShowHabitLog(t *testing.T) {
 	// Create test data
 	habits := []*storage.Habit{
@@ -281,7 +465,7 @@ func TestDisplayShowTodos(t *testing.T) {
 	os.Stdout = w
 
 	display := ui.NewDisplay(true) // no color for testing
-	display.ShowTodos(habits, entries, 20)
+	display.ShowTodos(habits, entries, 20, 8)
 
 	// Restore stdout
 	w.Close()
@@ -298,6 +482,40 @@ func TestDisplayShowTodos(t *testing.T) {
 	}
 }
 
+func TestDisplayShowTidyResult(t *testing.T) {
+	result := &storage.TidyResult{
+		Entries:    4,
+		Moved:      1,
+		Duplicates: []string{"2025-01-02 : Gym : n :  : "},
+		Orphans:    []string{"2025-01-01 : Old : y :  : ", "2025-01-02 : Old : y :  : "},
+		Changed:    true,
+	}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	display := ui.NewDisplay(true) // no color for testing
+	display.ShowTidyResult(result, true)
+
+	w.Close()
+	os.Stdout = old
+
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	for _, want := range []string{
+		"1 entry would be reordered by date and habit\n",
+		"1 superseded duplicate would be removed:\n",
+		"2 entries for habits not in your habits file would be archived:\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got:\n%s", want, output)
+		}
+	}
+}
+
 func TestInputOnboard(t *testing.T) {
 	// Create a mock input
 	input := ui.NewInput(true)
@@ -348,7 +566,7 @@ func TestMockRepository(t *testing.T) {
 
 	// Test WriteEntry
 	testDate := civil.Date{Year: 2025, Month: 1, Day: 15}
-	err = mockRepo.WriteEntry(testDate, "Test", "y", "comment", "1.0")
+	err = mockRepo.WriteEntry(testDate, "Test", "y", "comment", "1.0", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -381,7 +599,11 @@ func (m *MockRepository) LoadEntries() (*storage.Entries, error) {
 	return m.entries, nil
 }
 
-func (m *MockRepository) WriteEntry(d civil.Date, habit string, result string, comment string, amount string) error {
+func (m *MockRepository) LoadHistory(from civil.Date) ([]*storage.ParseError, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) WriteEntry(d civil.Date, habit string, result string, comment string, amount string, timeOfDay string) error {
 	famount := 0.0
 	if amount != "" {
 		// In a real implementation, we'd parse the amount
@@ -391,14 +613,36 @@ func (m *MockRepository) WriteEntry(d civil.Date, habit string, result string, c
 		Result:  result,
 		Comment: comment,
 		Amount:  famount,
+		Time:    timeOfDay,
 	}
 	return nil
 }
 
+func (m *MockRepository) UpdateEntry(d civil.Date, habit string, result string, comment string, amount string, timeOfDay string) error {
+	key := storage.DailyHabit{Day: d, Habit: habit}
+	if _, ok := (*m.entries)[key]; !ok {
+		return storage.ErrEntryNotFound
+	}
+	return m.WriteEntry(d, habit, result, comment, amount, timeOfDay)
+}
+
+func (m *MockRepository) DeleteEntry(d civil.Date, habit string) error {
+	key := storage.DailyHabit{Day: d, Habit: habit}
+	if _, ok := (*m.entries)[key]; !ok {
+		return storage.ErrEntryNotFound
+	}
+	delete(*m.entries, key)
+	return nil
+}
+
 func (m *MockRepository) GetConfigDir() string {
 	return "/tmp/test"
 }
 
+func (m *MockRepository) GetLogDir() string {
+	return "/tmp/test"
+}
+
 func (m *MockRepository) InitializeConfig() error {
 	return nil
 }
@@ -432,4 +676,76 @@ func TestHabitStatsStruct(t *testing.T) {
 	if stats.Skips != 2 {
 		t.Errorf("Expected 2 skips, got %d", stats.Skips)
 	}
-}
\ No newline at end of file
+}
+
+func TestGetTodosNotStarted(t *testing.T) {
+	start := civil.Date{Year: 2025, Month: 1, Day: 14}
+	habits := []*storage.Habit{
+		{Name: "Planned", Target: 1, Interval: 1, StartRecord: start, FirstRecord: start},
+	}
+
+	todos := ui.GetTodos(habits, &storage.Entries{}, civil.Date{Year: 2025, Month: 1, Day: 15}, 3)
+	for date := range todos {
+		if date < start.String() {
+			t.Errorf("Planned should not be a todo on %s, before its start date", date)
+		}
+	}
+	if len(todos) != 2 {
+		t.Errorf("Expected todos on the 2 days since the start date, got %v", todos)
+	}
+}
+
+func TestGetTodosPaused(t *testing.T) {
+	first := civil.Date{Year: 2025, Month: 1, Day: 1}
+	habits := []*storage.Habit{
+		{Name: "Travel", Target: 1, Interval: 1, FirstRecord: first,
+			Pauses: []storage.Pause{{From: civil.Date{Year: 2025, Month: 1, Day: 13}, To: civil.Date{Year: 2025, Month: 1, Day: 14}}}},
+	}
+
+	todos := ui.GetTodos(habits, &storage.Entries{}, civil.Date{Year: 2025, Month: 1, Day: 15}, 3)
+	for _, date := range []string{"2025-01-13", "2025-01-14"} {
+		if _, ok := todos[date]; ok {
+			t.Errorf("Travel should not be a todo on %s, while it is paused", date)
+		}
+	}
+	if len(todos) != 2 {
+		t.Errorf("Expected todos on the 2 days not paused, got %v", todos)
+	}
+}
+
+func TestGetTodosWeekdays(t *testing.T) {
+	// 2025-01-13 is a Monday
+	habits := []*storage.Habit{
+		{Name: "Trash out", Target: 1, Interval: 1, FirstRecord: civil.Date{Year: 2025, Month: 1, Day: 1},
+			Weekdays: []time.Weekday{time.Tuesday}},
+	}
+
+	todos := ui.GetTodos(habits, &storage.Entries{}, civil.Date{Year: 2025, Month: 1, Day: 15}, 3)
+	if len(todos) != 1 || len(todos["2025-01-14"]) != 1 {
+		t.Errorf("Expected a todo on Tuesday 2025-01-14 only, got %v", todos)
+	}
+}
+
+func TestGetTodosAvoid(t *testing.T) {
+	to := civil.Date{Year: 2025, Month: 1, Day: 15}
+	habits := []*storage.Habit{
+		{Name: "Too much coffee", Target: 1, Interval: 1, Avoid: true},
+		{Name: "Takeaway", Target: 1, Interval: 7, Avoid: true, Limit: 2},
+		{Name: "Read", Target: 1, Interval: 1},
+	}
+	entries := &storage.Entries{
+		storage.DailyHabit{Day: to.AddDays(-3), Habit: "Read"}:     {Result: "y"},
+		storage.DailyHabit{Day: to.AddDays(-2), Habit: "Takeaway"}: {Result: "y"},
+	}
+	entries.FirstRecords(to.AddDays(-365), to, habits)
+
+	todos := ui.GetTodos(habits, entries, to, 3)
+	for date, names := range todos {
+		if len(names) != 1 || names[0] != "Read" {
+			t.Errorf("Expected only Read to be a todo on %s, got %v", date, names)
+		}
+	}
+	if len(todos) != 3 {
+		t.Errorf("Expected Read to be a todo on the 3 days since it was logged, got %v", todos)
+	}
+}